@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/sekruse/adventofcode2025/day01"
 	"github.com/spf13/cobra"
@@ -12,7 +13,7 @@ var day1Round1Cmd = &cobra.Command{
 	Short: "Part 1 of day 01.",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		res, err := day01.Round1(args[0], verbose)
+		res, err := day01.Round1(args[0], day1Opts, verbose)
 		if err != nil {
 			return err
 		}
@@ -26,7 +27,7 @@ var day1Round2Cmd = &cobra.Command{
 	Short: "Part 2 of day 2.",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		res, err := day01.Round2(args[0], verbose)
+		res, err := day01.Round2(args[0], day1Opts, verbose)
 		if err != nil {
 			return err
 		}
@@ -34,3 +35,78 @@ var day1Round2Cmd = &cobra.Command{
 		return nil
 	},
 }
+
+var (
+	day1Opts   = day01.DefaultOptions
+	day1Format string
+	day1Diff   bool
+)
+
+var day1Cmd = &cobra.Command{
+	Use:   "d01",
+	Short: "Tools for day 1.",
+}
+
+var day1TraceCmd = &cobra.Command{
+	Use:   "trace <input>",
+	Short: "Prints how each instruction turns the dial as CSV or JSON.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		instructions, err := day01.LoadSafeInstructions(args[0])
+		if err != nil {
+			return err
+		}
+		dial, err := day01.NewDial(day1Opts)
+		if err != nil {
+			return err
+		}
+		steps := dial.Trace(instructions)
+		if day1Diff {
+			steps = day01.RulesDiff(steps)
+		}
+		switch day1Format {
+		case "csv":
+			return day01.WriteCSV(os.Stdout, steps)
+		case "json":
+			return day01.WriteJSON(os.Stdout, steps)
+		}
+		return fmt.Errorf("unknown format %q", day1Format)
+	},
+}
+
+var day1SynthCmd = &cobra.Command{
+	Use:   "synth <goal>...",
+	Short: "Prints the instructions with the fewest clicks that reach the goals one after the other.",
+	Long: `Prints the instructions with the fewest clicks that reach the goals one after the other, in the input format.
+
+Each goal is the position that the dial should point at after the instruction and the number of clicks of the
+instruction that should end on a target, separated by a colon. A * stands for any. For example, "0:2" lands on 0 after
+a full revolution, and "*:0" turns the dial without passing any target.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		goals := make([]day01.Goal, len(args))
+		for k, arg := range args {
+			var err error
+			if goals[k], err = day01.ParseGoal(arg); err != nil {
+				return err
+			}
+		}
+		instructions, err := day01.Synthesize(day1Opts, goals)
+		if err != nil {
+			return err
+		}
+		return day01.WriteInstructions(os.Stdout, instructions)
+	},
+}
+
+func init() {
+	day1TraceCmd.Flags().StringVar(&day1Format, "format", "csv", "output format (csv|json)")
+	day1TraceCmd.Flags().BoolVar(&day1Diff, "diff", false, "only print the steps that rounds 1 and 2 count differently")
+	day1Cmd.AddCommand(day1TraceCmd)
+	day1Cmd.AddCommand(day1SynthCmd)
+	for _, cmd := range []*cobra.Command{day1Round1Cmd, day1Round2Cmd, day1TraceCmd, day1SynthCmd} {
+		cmd.Flags().IntVar(&day1Opts.Size, "size", day01.DefaultOptions.Size, "number of positions on the dial")
+		cmd.Flags().IntVar(&day1Opts.Start, "start", day01.DefaultOptions.Start, "position that the dial points at initially")
+		cmd.Flags().IntSliceVar(&day1Opts.Targets, "targets", day01.DefaultOptions.Targets, "positions to count")
+	}
+}
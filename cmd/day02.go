@@ -1,7 +1,7 @@
 package cmd
 
 import (
-	"fmt"
+	"os"
 
 	"github.com/sekruse/adventofcode2025/day02"
 	"github.com/spf13/cobra"
@@ -9,15 +9,10 @@ import (
 
 var day2Round1Cmd = &cobra.Command{
 	Use:   "d02r1",
-	Short: "Part 1 of day 01.",
+	Short: "Part 1 of day 2.",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		res, err := day02.Round1(args[0], verbose)
-		if err != nil {
-			return err
-		}
-		fmt.Println(res)
-		return nil
+		return runDay2(&day2Round1Query, args[0])
 	},
 }
 
@@ -26,11 +21,37 @@ var day2Round2Cmd = &cobra.Command{
 	Short: "Part 2 of day 2.",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		res, err := day02.Round2(args[0], verbose)
-		if err != nil {
-			return err
+		if day2Enumerate {
+			day2Aggregate = day02.List.String()
 		}
-		fmt.Println(res)
-		return nil
+		return runDay2(&day2Round2Query, args[0])
 	},
 }
+
+func runDay2(query *day02.Query, path string) error {
+	agg, err := day02.ParseAggregate(day2Aggregate)
+	if err != nil {
+		return err
+	}
+	return query.Run(path, agg, os.Stdout, verbose)
+}
+
+var (
+	day2Round1Query = *day02.Round1Query
+	day2Round2Query = *day02.Round2Query
+	day2Aggregate   string
+	day2Enumerate   bool
+)
+
+func init() {
+	for _, c := range []struct {
+		cmd   *cobra.Command
+		query *day02.Query
+	}{{day2Round1Cmd, &day2Round1Query}, {day2Round2Cmd, &day2Round2Query}} {
+		c.cmd.Flags().IntVar(&c.query.Base, "base", c.query.Base, "base in which IDs repeat a token (2-36)")
+		c.cmd.Flags().IntVar(&c.query.MinReps, "min-reps", c.query.MinReps, "minimum number of repetitions of the token")
+		c.cmd.Flags().IntVar(&c.query.MaxReps, "max-reps", c.query.MaxReps, "maximum number of repetitions of the token, 0 for no bound")
+		c.cmd.Flags().StringVar(&day2Aggregate, "aggregate", "sum", "what to print about the invalid IDs: sum, count or list")
+	}
+	day2Round2Cmd.Flags().BoolVar(&day2Enumerate, "enumerate", false, "print the invalid IDs instead of their sum, same as --aggregate list")
+}
@@ -12,7 +12,7 @@ var day3Round1Cmd = &cobra.Command{
 	Short: "Part 1 of day 3.",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		res, err := day03.Round1(args[0], verbose)
+		res, err := day03.Sum(args[0], day3Round1Digits, verbose)
 		if err != nil {
 			return err
 		}
@@ -26,7 +26,7 @@ var day3Round2Cmd = &cobra.Command{
 	Short: "Part 2 of day 3.",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		res, err := day03.Round2(args[0], verbose)
+		res, err := day03.Sum(args[0], day3Round2Digits, verbose)
 		if err != nil {
 			return err
 		}
@@ -34,3 +34,10 @@ var day3Round2Cmd = &cobra.Command{
 		return nil
 	},
 }
+
+var day3Round1Digits, day3Round2Digits int
+
+func init() {
+	day3Round1Cmd.Flags().IntVar(&day3Round1Digits, "digits", 2, "number of batteries to turn on per bank")
+	day3Round2Cmd.Flags().IntVar(&day3Round2Digits, "digits", 12, "number of batteries to turn on per bank")
+}
@@ -12,7 +12,11 @@ var day4Round1Cmd = &cobra.Command{
 	Short: "Part 1 of day 4.",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		res, err := day04.Round1(args[0], verbose)
+		rule, err := day4Rule()
+		if err != nil {
+			return err
+		}
+		res, err := day04.Round1(args[0], rule, verbose)
 		if err != nil {
 			return err
 		}
@@ -26,7 +30,11 @@ var day4Round2Cmd = &cobra.Command{
 	Short: "Part 2 of day 4.",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		res, err := day04.Round2(args[0], verbose)
+		rule, err := day4Rule()
+		if err != nil {
+			return err
+		}
+		res, err := day04.Round2(args[0], rule, verbose)
 		if err != nil {
 			return err
 		}
@@ -34,3 +42,25 @@ var day4Round2Cmd = &cobra.Command{
 		return nil
 	},
 }
+
+var (
+	day4Threshold    int
+	day4Neighborhood string
+	day4InPlace      bool
+)
+
+func day4Rule() (day04.Rule, error) {
+	neighborhood, err := day04.ParseNeighborhood(day4Neighborhood)
+	if err != nil {
+		return day04.Rule{}, err
+	}
+	return day04.Rule{Threshold: day4Threshold, Neighborhood: neighborhood, InPlace: day4InPlace}, nil
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{day4Round1Cmd, day4Round2Cmd} {
+		cmd.Flags().IntVar(&day4Threshold, "threshold", day04.DefaultRule.Threshold, "number of neighboring rolls from which on a roll cannot be removed")
+		cmd.Flags().StringVar(&day4Neighborhood, "neighborhood", day04.DefaultRule.Neighborhood.String(), "cells that count as neighbors: moore or von-neumann")
+		cmd.Flags().BoolVar(&day4InPlace, "in-place", day04.DefaultRule.InPlace, "remove rolls as soon as they are found instead of once per wave")
+	}
+}
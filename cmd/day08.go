@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/sekruse/adventofcode2025/day08"
 	"github.com/spf13/cobra"
@@ -12,7 +13,11 @@ var day8Round1Cmd = &cobra.Command{
 	Short: "Part 1 of day 8.",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		res, err := day08.Round1(args[0], 1000, 3, verbose)
+		metric, err := day08.ParseMetric(day8Metric)
+		if err != nil {
+			return err
+		}
+		res, err := day08.Round1(args[0], day8Pairs, day8Clusters, metric, verbose)
 		if err != nil {
 			return err
 		}
@@ -26,7 +31,11 @@ var day8Round2Cmd = &cobra.Command{
 	Short: "Part 2 of day 8.",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		res, err := day08.Round2(args[0], verbose)
+		metric, err := day08.ParseMetric(day8Metric)
+		if err != nil {
+			return err
+		}
+		res, err := day08.Round2(args[0], metric, verbose)
 		if err != nil {
 			return err
 		}
@@ -34,3 +43,50 @@ var day8Round2Cmd = &cobra.Command{
 		return nil
 	},
 }
+
+var (
+	day8Pairs    int
+	day8Clusters int
+	day8Metric   string
+	day8Format   string
+)
+
+var day8Cmd = &cobra.Command{
+	Use:   "d08",
+	Short: "Tools for day 8.",
+}
+
+var day8DendrogramCmd = &cobra.Command{
+	Use:   "dendrogram <input>",
+	Short: "Prints the single-linkage clustering of the junction boxes as Newick or JSON.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		metric, err := day08.ParseMetric(day8Metric)
+		if err != nil {
+			return err
+		}
+		points, err := day08.LoadPoints(args[0])
+		if err != nil {
+			return err
+		}
+		d := day08.NewDendrogram(points, metric)
+		switch day8Format {
+		case "newick":
+			fmt.Println(d.Newick())
+			return nil
+		case "json":
+			return d.WriteJSON(os.Stdout)
+		}
+		return fmt.Errorf("unknown format %q", day8Format)
+	},
+}
+
+func init() {
+	day8Round1Cmd.Flags().IntVar(&day8Pairs, "pairs", 1000, "number of closest pairs to connect")
+	day8Round1Cmd.Flags().IntVar(&day8Clusters, "clusters", 3, "number of largest circuits to multiply the sizes of")
+	for _, cmd := range []*cobra.Command{day8Round1Cmd, day8Round2Cmd, day8DendrogramCmd} {
+		cmd.Flags().StringVar(&day8Metric, "metric", "euclidean", "distance between junction boxes: euclidean, manhattan or chebyshev")
+	}
+	day8DendrogramCmd.Flags().StringVar(&day8Format, "format", "newick", "output format (newick|json)")
+	day8Cmd.AddCommand(day8DendrogramCmd)
+}
@@ -26,7 +26,7 @@ var day9Round2Cmd = &cobra.Command{
 	Short: "Part 2 of day 9.",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		res, err := day09.Round2(args[0], verbose)
+		res, err := day09.Round2(args[0], day9Lenient, verbose)
 		if err != nil {
 			return err
 		}
@@ -34,3 +34,32 @@ var day9Round2Cmd = &cobra.Command{
 		return nil
 	},
 }
+
+var (
+	day9Lenient    bool
+	day9RenderOpts day09.RenderOptions
+)
+
+var day9Cmd = &cobra.Command{
+	Use:   "d09",
+	Short: "Tools for day 9.",
+}
+
+var day9RenderCmd = &cobra.Command{
+	Use:   "render <input>",
+	Short: "Draws the polygon and the largest rectangles of both rounds as SVG and PNG.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return day09.Render(args[0], day9RenderOpts, verbose)
+	},
+}
+
+func init() {
+	day9RenderCmd.Flags().StringVar(&day9RenderOpts.SVGPath, "svg", "day09.svg", "SVG file to write, empty to skip")
+	day9RenderCmd.Flags().StringVar(&day9RenderOpts.PNGPath, "png", "day09.png", "PNG file to write, empty to skip")
+	day9RenderCmd.Flags().IntVar(&day9RenderOpts.Size, "size", 1000, "width and height of the images in pixels")
+	day9RenderCmd.Flags().BoolVar(&day9RenderOpts.Labels, "labels", false, "label the red tiles with their coordinates (SVG only)")
+	day9RenderCmd.Flags().BoolVar(&day9RenderOpts.Lenient, "lenient", false, "only warn if the red tiles do not form a closed rectilinear polygon without crossings, at the risk of a wrong answer")
+	day9Round2Cmd.Flags().BoolVar(&day9Lenient, "lenient", false, "only warn if the red tiles do not form a closed rectilinear polygon without crossings, at the risk of a wrong answer")
+	day9Cmd.AddCommand(day9RenderCmd)
+}
@@ -7,12 +7,14 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var day12Opts day12.Options
+
 var day12Round1Cmd = &cobra.Command{
 	Use:   "d12r1",
 	Short: "Part 1 of day 12.",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		res, err := day12.Round1(args[0], verbose)
+		res, err := day12.Round1(args[0], day12Opts, verbose)
 		if err != nil {
 			return err
 		}
@@ -20,3 +22,28 @@ var day12Round1Cmd = &cobra.Command{
 		return nil
 	},
 }
+
+var day12ValidateCmd = &cobra.Command{
+	Use:   "validate <input> <solutions>",
+	Short: "Checks the layouts written by d12r1 --solutions against the input.",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		res, err := day12.Validate(args[0], args[1])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%d valid layouts\n", res)
+		return nil
+	},
+}
+
+func init() {
+	day12Round1Cmd.Flags().StringVar(&day12Opts.Solver, "solver", "backtracking", "packing algorithm: backtracking, dlx or sat")
+	day12Round1Cmd.Flags().BoolVar(&day12Opts.Visualize, "visualize", false, "step through the searches on the terminal")
+	day12Round1Cmd.Flags().StringVar(&day12Opts.DIMACSDir, "dimacs", "", "directory to export the SAT encoding of each region to")
+	day12Round1Cmd.Flags().StringVar(&day12Opts.SolutionsPath, "solutions", "", "file to write the layout of each feasible region to")
+	day12Round1Cmd.Flags().IntVar(&day12Opts.Workers, "workers", 0, "number of regions to solve concurrently (0 means one per CPU)")
+	day12Round1Cmd.Flags().DurationVar(&day12Opts.Timeout, "timeout", 0, "time budget for the search on each region (0 means no limit)")
+	day12Round1Cmd.Flags().BoolVar(&day12Opts.CountUnknown, "count-unknown", false, "count regions that run out of time as feasible")
+	day12Round1Cmd.AddCommand(day12ValidateCmd)
+}
@@ -23,6 +23,7 @@ func init() {
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "verbose output")
 	rootCmd.AddCommand(day1Round1Cmd)
 	rootCmd.AddCommand(day1Round2Cmd)
+	rootCmd.AddCommand(day1Cmd)
 	rootCmd.AddCommand(day2Round1Cmd)
 	rootCmd.AddCommand(day2Round2Cmd)
 	rootCmd.AddCommand(day3Round1Cmd)
@@ -37,11 +38,14 @@ func init() {
 	rootCmd.AddCommand(day7Round2Cmd)
 	rootCmd.AddCommand(day8Round1Cmd)
 	rootCmd.AddCommand(day8Round2Cmd)
+	rootCmd.AddCommand(day8Cmd)
 	rootCmd.AddCommand(day9Round1Cmd)
 	rootCmd.AddCommand(day9Round2Cmd)
+	rootCmd.AddCommand(day9Cmd)
 	rootCmd.AddCommand(day10Round1Cmd)
 	rootCmd.AddCommand(day10Round2Cmd)
 	rootCmd.AddCommand(day11Round1Cmd)
 	rootCmd.AddCommand(day11Round2Cmd)
 	rootCmd.AddCommand(day12Round1Cmd)
+	rootCmd.AddCommand(satCmd)
 }
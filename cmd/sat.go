@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/sekruse/adventofcode2025/sat"
+	"github.com/spf13/cobra"
+)
+
+var satCmd = &cobra.Command{
+	Use:   "sat",
+	Short: "Solves a CNF formula in DIMACS format, e.g., one exported with d12r1 --dimacs.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		file, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		f, err := sat.ReadDIMACS(file)
+		if err != nil {
+			return err
+		}
+		solver := sat.NewSolver(f)
+		status := solver.Solve(nil)
+		if verbose {
+			fmt.Printf("c %d conflicts, %d decisions\n", solver.Conflicts, solver.Decisions)
+		}
+		return sat.WriteModel(os.Stdout, status, solver.Model())
+	},
+}
@@ -0,0 +1,107 @@
+package day01
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Options configures the dial of the safe.
+type Options struct {
+	// Size is the number of positions, which are numbered from 0 to Size-1.
+	Size int
+	// Start is the position that the dial points at initially.
+	Start int
+	// Targets are the positions to count. Round 1 counts how often the dial lands on them, and Round 2 how often it
+	// points at them during any click.
+	Targets []int
+}
+
+// DefaultOptions describe the dial from the puzzle.
+var DefaultOptions = Options{Size: 100, Start: 50, Targets: []int{0}}
+
+// Dial simulates the dial of the safe and counts how often it points at the targets.
+type Dial struct {
+	size     int
+	position int
+	targets  []int
+	// Landings is the number of instructions that ended on a target.
+	Landings int
+	// Passes is the number of clicks that ended on a target, including those that ended an instruction. An instruction
+	// can pass a target several times by turning full revolutions.
+	Passes int
+}
+
+func NewDial(opts Options) (*Dial, error) {
+	if opts.Size <= 0 {
+		return nil, fmt.Errorf("expected a positive size, got %d", opts.Size)
+	}
+	if opts.Start < 0 || opts.Start >= opts.Size {
+		return nil, fmt.Errorf("start %d is not a position of a dial of size %d", opts.Start, opts.Size)
+	}
+	if len(opts.Targets) == 0 {
+		return nil, fmt.Errorf("expected at least one target")
+	}
+	targets := slices.Clone(opts.Targets)
+	slices.Sort(targets)
+	for i, t := range targets {
+		if t < 0 || t >= opts.Size {
+			return nil, fmt.Errorf("target %d is not a position of a dial of size %d", t, opts.Size)
+		}
+		if i > 0 && targets[i-1] == t {
+			return nil, fmt.Errorf("target %d repeats", t)
+		}
+	}
+	return &Dial{size: opts.Size, position: opts.Start, targets: targets}, nil
+}
+
+// Position returns the position that the dial points at.
+func (d *Dial) Position() int {
+	return d.position
+}
+
+// Apply turns the dial by the instruction, updates the counts and returns what happened.
+func (d *Dial) Apply(i *SafeInstruction) Step {
+	step := Step{
+		Instruction: i.String(),
+		Clicks:      i.Clicks,
+		Before:      d.position,
+		Revolutions: i.Clicks / d.size,
+	}
+	passes := countPasses(d.size, d.position, i, d.targets)
+	d.position = mod(d.position+int(i.Direction)*i.Clicks, d.size)
+	step.After = d.position
+	step.Landed = slices.Contains(d.targets, d.position)
+	step.Crossings = passes
+	if step.Landed {
+		d.Landings++
+		// Unless the instruction has no clicks at all, its last click is one of the passes.
+		if passes > 0 {
+			step.Crossings--
+		}
+	}
+	d.Passes += passes
+	step.Landings, step.Passes = d.Landings, d.Passes
+	return step
+}
+
+// countPasses returns the number of clicks of the instruction that end on any of the targets when starting at the
+// position.
+func countPasses(size, position int, i *SafeInstruction, targets []int) int {
+	var res int
+	for _, t := range targets {
+		// The dial first reaches the target after this many clicks, and then again after each full revolution.
+		first := mod(int(i.Direction)*(t-position), size)
+		if first == 0 {
+			first = size
+		}
+		if first <= i.Clicks {
+			res += (i.Clicks-first)/size + 1
+		}
+	}
+	return res
+}
+
+// mod returns a modulo n, which unlike a % n is never negative.
+func mod(a, n int) int {
+	return (a%n + n) % n
+}
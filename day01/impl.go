@@ -8,58 +8,41 @@ import (
 	"strconv"
 )
 
-const (
-	dialInit      int = 50
-	dialPositions int = 100
-)
-
-func Round1(path string, verbose bool) (int, error) {
-	instructions, err := LoadSafeInstructions(path)
+// Round1 counts how often an instruction leaves the dial pointing at a target.
+func Round1(path string, opts Options, verbose bool) (int, error) {
+	dial, err := run(path, opts, func(d *Dial) int { return d.Landings }, verbose)
 	if err != nil {
 		return 0, err
 	}
-	// Run the simulation.
-	counter := 0
-	dial := 50
-	for _, i := range instructions {
-		dial = (dial + int(i.Direction)*i.Clicks + dialPositions) % dialPositions
-		if dial == 0 {
-			counter++
-		}
-		if verbose {
-			fmt.Printf("Turn: %5s -> Dial: %3d. Counter: %5d.\n", i, dial, counter)
-		}
+	return dial.Landings, nil
+}
+
+// Round2 counts how often the dial points at a target after any click.
+func Round2(path string, opts Options, verbose bool) (int, error) {
+	dial, err := run(path, opts, func(d *Dial) int { return d.Passes }, verbose)
+	if err != nil {
+		return 0, err
 	}
-	return counter, nil
+	return dial.Passes, nil
 }
 
-func Round2(path string, verbose bool) (int, error) {
+// run applies all instructions to a new dial. The counter is only for the verbose output.
+func run(path string, opts Options, counter func(d *Dial) int, verbose bool) (*Dial, error) {
 	instructions, err := LoadSafeInstructions(path)
 	if err != nil {
-		return 0, err
+		return nil, err
+	}
+	dial, err := NewDial(opts)
+	if err != nil {
+		return nil, err
 	}
-	// Run the simulation.
-	counter := 0
-	dial := 50
 	for _, i := range instructions {
-		// Every full revolution necessarily passes the 0 exactly once.
-		fullRevolutions := i.Clicks / dialPositions
-		counter += fullRevolutions
-		// We check if the remaining motion either crosses the 0 or lands on it.
-		remainingClicks := i.Clicks - fullRevolutions*dialPositions
-		if remainingClicks > 0 {
-			dialWasZero := dial == 0
-			overshootDial := dial + int(i.Direction)*remainingClicks
-			dial = (overshootDial + dialPositions) % dialPositions
-			if dial == 0 || (!dialWasZero && dial != overshootDial) {
-				counter++
-			}
-		}
+		dial.Apply(i)
 		if verbose {
-			fmt.Printf("Turn: %5s -> Dial: %3d. Counter: %5d.\n", i, dial, counter)
+			fmt.Printf("Turn: %5s -> Dial: %3d. Counter: %5d.\n", i, dial.Position(), counter(dial))
 		}
 	}
-	return counter, nil
+	return dial, nil
 }
 
 type Direction int
@@ -78,7 +61,13 @@ type SafeInstruction struct {
 }
 
 func (s *SafeInstruction) String() string {
-	return s.code
+	if s.code != "" {
+		return s.code
+	}
+	if s.Direction == Left {
+		return fmt.Sprintf("L%d", s.Clicks)
+	}
+	return fmt.Sprintf("R%d", s.Clicks)
 }
 
 func ParseSafeInstruction(code string) (*SafeInstruction, error) {
@@ -1,7 +1,12 @@
 package day01
 
 import (
+	"encoding/json"
+	"math/rand/v2"
+	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -9,7 +14,7 @@ func TestResults(t *testing.T) {
 	testFilePath := filepath.Join("testdata", "example.txt")
 	t.Run("Round 1", func(t *testing.T) {
 		const want = 3
-		got, err := Round1(testFilePath, false)
+		got, err := Round1(testFilePath, DefaultOptions, false)
 		if err != nil {
 			t.Fatalf("Unexpected error: %v", err)
 		}
@@ -19,7 +24,7 @@ func TestResults(t *testing.T) {
 	})
 	t.Run("Round 2", func(t *testing.T) {
 		const want = 6
-		got, err := Round2(testFilePath, false)
+		got, err := Round2(testFilePath, DefaultOptions, false)
 		if err != nil {
 			t.Fatalf("Unexpected error: %v", err)
 		}
@@ -28,3 +33,257 @@ func TestResults(t *testing.T) {
 		}
 	})
 }
+
+func TestDial(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 1))
+	for range 100 {
+		opts := Options{Size: 1 + rng.IntN(20)}
+		opts.Start = rng.IntN(opts.Size)
+		for p := range opts.Size {
+			if rng.IntN(3) == 0 {
+				opts.Targets = append(opts.Targets, p)
+			}
+		}
+		if len(opts.Targets) == 0 {
+			opts.Targets = []int{0}
+		}
+		dial, err := NewDial(opts)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		// Turn the dial click by click.
+		var landings, passes int
+		position := opts.Start
+		for range 20 {
+			i := &SafeInstruction{Direction: Left, Clicks: rng.IntN(3 * opts.Size)}
+			if rng.IntN(2) == 0 {
+				i.Direction = Right
+			}
+			dial.Apply(i)
+			for range i.Clicks {
+				position = (position + int(i.Direction) + opts.Size) % opts.Size
+				if slices.Contains(opts.Targets, position) {
+					passes++
+				}
+			}
+			if slices.Contains(opts.Targets, position) {
+				landings++
+			}
+		}
+		if dial.Position() != position || dial.Landings != landings || dial.Passes != passes {
+			t.Errorf("%+v: got position %d, %d landings and %d passes, want %d, %d and %d",
+				opts, dial.Position(), dial.Landings, dial.Passes, position, landings, passes)
+		}
+	}
+}
+
+func TestNewDial(t *testing.T) {
+	for _, opts := range []Options{
+		{Size: 0, Start: 0, Targets: []int{0}},
+		{Size: 10, Start: 10, Targets: []int{0}},
+		{Size: 10, Start: 5},
+		{Size: 10, Start: 5, Targets: []int{-1}},
+		{Size: 10, Start: 5, Targets: []int{3, 3}},
+	} {
+		if _, err := NewDial(opts); err == nil {
+			t.Errorf("%+v: got no error", opts)
+		}
+	}
+}
+
+func TestTrace(t *testing.T) {
+	instructions, err := LoadSafeInstructions(filepath.Join("testdata", "example.txt"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// Go to 0, stay there without clicks, then leave it by a full revolution and a bit.
+	instructions = append(instructions,
+		&SafeInstruction{Direction: Left, Clicks: 32},
+		&SafeInstruction{Direction: Right, Clicks: 0},
+		&SafeInstruction{Direction: Left, Clicks: 105})
+	dial, err := NewDial(DefaultOptions)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	steps := slices.Collect(dial.Trace(instructions))
+	if len(steps) != len(instructions) {
+		t.Fatalf("got %d steps, want %d", len(steps), len(instructions))
+	}
+	last := steps[len(steps)-1]
+	if want := (Step{Number: 13, Instruction: "L105", Clicks: 105, Before: 0, After: 95, Revolutions: 1, Crossings: 1, Landings: 5, Passes: 8}); last != want {
+		t.Errorf("got last step %+v, want %+v", last, want)
+	}
+	var numbers []int
+	for s := range RulesDiff(slices.Values(steps)) {
+		numbers = append(numbers, s.Number)
+	}
+	if want := []int{1, 5, 10, 12, 13}; !slices.Equal(numbers, want) {
+		t.Errorf("got steps %v counted differently, want %v", numbers, want)
+	}
+	var sb strings.Builder
+	if err := WriteCSV(&sb, slices.Values(steps)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(sb.String()), "\n")
+	if got, want := lines[len(lines)-1], "13,L105,105,0,95,1,1,false,5,8"; len(lines) != len(steps)+1 || got != want {
+		t.Errorf("got %d lines ending in %q, want %d ending in %q", len(lines), got, len(steps)+1, want)
+	}
+	sb.Reset()
+	if err := WriteJSON(&sb, slices.Values(steps)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var decoded []Step
+	if err := json.Unmarshal([]byte(sb.String()), &decoded); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !slices.Equal(decoded, steps) {
+		t.Errorf("got %v from JSON, want %v", decoded, steps)
+	}
+}
+
+func randomInstructions(rng *rand.Rand, n, maxClicks int) []*SafeInstruction {
+	res := make([]*SafeInstruction, n)
+	for k := range res {
+		res[k] = &SafeInstruction{Direction: Left, Clicks: rng.IntN(maxClicks + 1)}
+		if rng.IntN(2) == 0 {
+			res[k].Direction = Right
+		}
+	}
+	return res
+}
+
+func totalClicks(instructions []*SafeInstruction) int {
+	var res int
+	for _, i := range instructions {
+		res += i.Clicks
+	}
+	return res
+}
+
+// stepPasses returns the number of passes of the step alone.
+func stepPasses(step Step) int {
+	if step.Landed && step.Clicks > 0 {
+		return step.Crossings + 1
+	}
+	return step.Crossings
+}
+
+// reaches tells whether the instructions reach the goals.
+func reaches(t *testing.T, opts Options, instructions []*SafeInstruction, goals []Goal) bool {
+	t.Helper()
+	dial, err := NewDial(opts)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(instructions) != len(goals) {
+		return false
+	}
+	for k, i := range instructions {
+		step := dial.Apply(i)
+		if goals[k].Position != Any && goals[k].Position != step.After || goals[k].Passes != Any && goals[k].Passes != stepPasses(step) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSynthesize(t *testing.T) {
+	rng := rand.New(rand.NewPCG(4, 4))
+	for range 50 {
+		opts := Options{Size: 2 + rng.IntN(10), Targets: []int{0}}
+		opts.Start = rng.IntN(opts.Size)
+		if rng.IntN(2) == 0 {
+			opts.Targets = append(opts.Targets, 1)
+		}
+		// Take the goals from random instructions, but leave some positions and passes open.
+		original := randomInstructions(rng, 5, 3*opts.Size)
+		dial, _ := NewDial(opts)
+		var goals []Goal
+		for step := range dial.Trace(original) {
+			g := Goal{Position: step.After, Passes: stepPasses(step)}
+			switch rng.IntN(4) {
+			case 0:
+				g.Position = Any
+			case 1:
+				g.Passes = Any
+			}
+			goals = append(goals, g)
+		}
+		got, err := Synthesize(opts, goals)
+		if err != nil {
+			t.Fatalf("%+v, %v: unexpected error: %v", opts, goals, err)
+		}
+		if !reaches(t, opts, got, goals) {
+			t.Errorf("%+v: %v does not reach %v", opts, got, goals)
+		}
+		if totalClicks(got) > totalClicks(original) {
+			t.Errorf("%+v: got %v with more clicks than %v for %v", opts, got, original, goals)
+		}
+	}
+}
+
+func TestSynthesizeShortest(t *testing.T) {
+	opts := Options{Size: 4, Start: 1, Targets: []int{0, 3}}
+	goals := []Goal{{Position: 0, Passes: 3}, {Position: Any, Passes: 2}, {Position: 2, Passes: Any}}
+	got, err := Synthesize(opts, goals)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// Try all instructions with up to three revolutions.
+	want := -1
+	var search func(prefix []*SafeInstruction)
+	search = func(prefix []*SafeInstruction) {
+		if len(prefix) == len(goals) {
+			if c := totalClicks(prefix); reaches(t, opts, prefix, goals) && (want < 0 || c < want) {
+				want = c
+			}
+			return
+		}
+		for clicks := range 3*opts.Size + 1 {
+			for _, dir := range []Direction{Left, Right} {
+				search(append(prefix, &SafeInstruction{Direction: dir, Clicks: clicks}))
+			}
+		}
+	}
+	search(nil)
+	if c := totalClicks(got); !reaches(t, opts, got, goals) || c != want {
+		t.Errorf("got %v with %d clicks, want %d clicks", got, c, want)
+	}
+}
+
+func TestSynthesizeRoundTrip(t *testing.T) {
+	var goals []Goal
+	// Land on 0 after a full revolution, stay there, leave it without passing it again and come back the other way.
+	for _, enc := range []string{"0:2", "0:0", "99", "*:1"} {
+		g, err := ParseGoal(enc)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		goals = append(goals, g)
+	}
+	instructions, err := Synthesize(DefaultOptions, goals)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "input.txt")
+	var sb strings.Builder
+	if err := WriteInstructions(&sb, instructions); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := "R150\nR0\nL1\nR1\n"; sb.String() != want {
+		t.Errorf("got %q, want %q", sb.String(), want)
+	}
+	if err := os.WriteFile(path, []byte(sb.String()), 0o644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	loaded, err := LoadSafeInstructions(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reaches(t, DefaultOptions, loaded, goals) {
+		t.Errorf("got %v after loading, which does not reach %v", loaded, goals)
+	}
+	if _, err := Synthesize(DefaultOptions, []Goal{{Position: 0, Passes: 0}}); err == nil {
+		t.Error("got no error for landing on 0 without passing it")
+	}
+}
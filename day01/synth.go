@@ -0,0 +1,171 @@
+package day01
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Any matches every position or number of passes in a Goal.
+const Any = -1
+
+// Goal describes what a single instruction should do to the dial.
+type Goal struct {
+	// Position is where the dial should point at after the instruction.
+	Position int
+	// Passes is the number of clicks of the instruction that should end on a target, as counted by Round2.
+	Passes int
+}
+
+// ParseGoal parses a position and a number of passes separated by a colon, where * stands for any. The number of
+// passes may be omitted, e.g., "0:2", "*:1", "95".
+func ParseGoal(enc string) (Goal, error) {
+	pos, passes, _ := strings.Cut(enc, ":")
+	if passes == "" {
+		passes = "*"
+	}
+	var g Goal
+	var err error
+	if g.Position, err = parseAny(pos); err != nil {
+		return Goal{}, fmt.Errorf("unexpected goal %q: %w", enc, err)
+	}
+	if g.Passes, err = parseAny(passes); err != nil {
+		return Goal{}, fmt.Errorf("unexpected goal %q: %w", enc, err)
+	}
+	return g, nil
+}
+
+func parseAny(enc string) (int, error) {
+	if enc == "*" {
+		return Any, nil
+	}
+	v, err := strconv.Atoi(enc)
+	if err != nil {
+		return 0, err
+	}
+	if v < 0 {
+		return 0, fmt.Errorf("expected a non-negative number, got %d", v)
+	}
+	return v, nil
+}
+
+func (g Goal) String() string {
+	format := func(v int) string {
+		if v == Any {
+			return "*"
+		}
+		return strconv.Itoa(v)
+	}
+	return format(g.Position) + ":" + format(g.Passes)
+}
+
+// Synthesize finds instructions that reach the goals one after the other, using as few clicks as possible in total.
+//
+// It keeps the fewest clicks to reach each position after each goal, so it takes time quadratic in the size of the
+// dial for goals with any position.
+func Synthesize(opts Options, goals []Goal) ([]*SafeInstruction, error) {
+	dial, err := NewDial(opts)
+	if err != nil {
+		return nil, err
+	}
+	n := dial.size
+	// clicks[p] is the fewest clicks to reach position p after the goals so far, or -1 if p is unreachable.
+	clicks := make([]int, n)
+	for p := range clicks {
+		clicks[p] = -1
+	}
+	clicks[dial.position] = 0
+	// via[k][p] is the instruction that reaches position p for the k-th goal, along with the previous position.
+	via := make([][]synthStep, len(goals))
+	for k, g := range goals {
+		if g.Position != Any && g.Position >= n {
+			return nil, fmt.Errorf("goal %d: %d is not a position of a dial of size %d", k+1, g.Position, n)
+		}
+		next := make([]int, n)
+		for p := range next {
+			next[p] = -1
+		}
+		via[k] = make([]synthStep, n)
+		for from, c := range clicks {
+			if c < 0 {
+				continue
+			}
+			for to := range n {
+				if g.Position != Any && g.Position != to {
+					continue
+				}
+				for _, dir := range []Direction{Right, Left} {
+					i, ok := dial.shortest(from, to, dir, g.Passes)
+					if !ok || next[to] >= 0 && next[to] <= c+i.Clicks {
+						continue
+					}
+					next[to] = c + i.Clicks
+					via[k][to] = synthStep{from: from, instruction: i}
+				}
+			}
+		}
+		clicks = next
+		if !reachable(clicks) {
+			return nil, fmt.Errorf("goal %d: no instruction reaches %s", k+1, g)
+		}
+	}
+	// Find the cheapest final position and go back from there.
+	end := -1
+	for p, c := range clicks {
+		if c >= 0 && (end < 0 || c < clicks[end]) {
+			end = p
+		}
+	}
+	res := make([]*SafeInstruction, len(goals))
+	for k := len(goals) - 1; k >= 0; k-- {
+		res[k] = via[k][end].instruction
+		end = via[k][end].from
+	}
+	return res, nil
+}
+
+type synthStep struct {
+	from        int
+	instruction *SafeInstruction
+}
+
+func reachable(clicks []int) bool {
+	for _, c := range clicks {
+		if c >= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// shortest returns the instruction with the fewest clicks in the direction that turns the dial from one position to
+// another with the given number of passes, or Any.
+func (d *Dial) shortest(from, to int, dir Direction, passes int) (*SafeInstruction, bool) {
+	i := &SafeInstruction{Direction: dir, Clicks: mod(int(dir)*(to-from), d.size)}
+	if passes == Any {
+		return i, true
+	}
+	// Every full revolution on top passes each target once more.
+	extra := passes - countPasses(d.size, from, i, d.targets)
+	if extra < 0 || extra%len(d.targets) != 0 {
+		return nil, false
+	}
+	revolutions := extra / len(d.targets)
+	if revolutions > (math.MaxInt-i.Clicks)/d.size {
+		return nil, false
+	}
+	i.Clicks += revolutions * d.size
+	return i, true
+}
+
+// WriteInstructions writes the instructions in the input format, one per line.
+func WriteInstructions(w io.Writer, instructions []*SafeInstruction) error {
+	bw := bufio.NewWriter(w)
+	for _, i := range instructions {
+		fmt.Fprintln(bw, i)
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,98 @@
+package day01
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"iter"
+	"strconv"
+)
+
+// Step records how an instruction turned the dial.
+type Step struct {
+	// Number is the position of the instruction, starting at 1.
+	Number      int    `json:"number"`
+	Instruction string `json:"instruction"`
+	Clicks      int    `json:"clicks"`
+	Before      int    `json:"before"`
+	After       int    `json:"after"`
+	// Revolutions is the number of full revolutions of the instruction.
+	Revolutions int `json:"revolutions"`
+	// Crossings is the number of clicks that ended on a target, except for the last one.
+	Crossings int `json:"crossings"`
+	// Landed tells whether the instruction ended on a target.
+	Landed bool `json:"landed"`
+	// Landings and Passes are the counts of the dial after the instruction, i.e., the counters of Round1 and Round2.
+	Landings int `json:"landings"`
+	Passes   int `json:"passes"`
+}
+
+// RulesDiffer tells whether Round1 and Round2 count the step differently. That is the case if the dial crossed a
+// target without stopping there, or if it stayed on a target without any clicks, which only counts as a landing.
+func (s Step) RulesDiffer() bool {
+	return s.Crossings > 0 || s.Landed && s.Clicks == 0
+}
+
+// Trace applies the instructions to the dial and yields a step for each.
+func (d *Dial) Trace(instructions []*SafeInstruction) iter.Seq[Step] {
+	return func(yield func(Step) bool) {
+		for n, i := range instructions {
+			step := d.Apply(i)
+			step.Number = n + 1
+			if !yield(step) {
+				return
+			}
+		}
+	}
+}
+
+// RulesDiff yields only the steps that Round1 and Round2 count differently.
+func RulesDiff(steps iter.Seq[Step]) iter.Seq[Step] {
+	return func(yield func(Step) bool) {
+		for s := range steps {
+			if s.RulesDiffer() && !yield(s) {
+				return
+			}
+		}
+	}
+}
+
+var csvHeader = []string{"number", "instruction", "clicks", "before", "after", "revolutions", "crossings", "landed", "landings", "passes"}
+
+// WriteCSV writes the steps with a header line.
+func WriteCSV(w io.Writer, steps iter.Seq[Step]) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for s := range steps {
+		record := []string{
+			strconv.Itoa(s.Number),
+			s.Instruction,
+			strconv.Itoa(s.Clicks),
+			strconv.Itoa(s.Before),
+			strconv.Itoa(s.After),
+			strconv.Itoa(s.Revolutions),
+			strconv.Itoa(s.Crossings),
+			strconv.FormatBool(s.Landed),
+			strconv.Itoa(s.Landings),
+			strconv.Itoa(s.Passes),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteJSON writes the steps as a JSON array.
+func WriteJSON(w io.Writer, steps iter.Seq[Step]) error {
+	res := []Step{}
+	for s := range steps {
+		res = append(res, s)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(res)
+}
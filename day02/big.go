@@ -0,0 +1,160 @@
+package day02
+
+import (
+	"container/heap"
+	"fmt"
+	"math/big"
+)
+
+// This file mirrors the int64 implementation on math/big for intervals that are too large for it.
+
+var bigOne = big.NewInt(1)
+
+// evalBig counts and sums the invalid IDs in the interval.
+func (q *Query) evalBig(interval *BigInterval, verbose bool) (count, sum *big.Int) {
+	count, sum = new(big.Int), new(big.Int)
+	elis := splitIntoEquilengthIntervalsBig(interval, q.Base)
+	if verbose {
+		fmt.Printf("Split %s into: %s\n", interval, elis)
+	}
+	for _, eli := range elis {
+		width := digits(eli.A, q.Base)
+		periods := q.periods(width)
+		if len(periods) == 0 {
+			continue
+		}
+		counts, sums := make(map[int]*big.Int), make(map[int]*big.Int)
+		for _, d := range divisors(width) {
+			counts[d], sums[d] = periodicBig(eli, q.Base, width, d)
+		}
+		eliCount := new(big.Int)
+		for _, p := range periods {
+			for _, d := range divisors(p) {
+				mu := big.NewInt(int64(moebius(p / d)))
+				eliCount.Add(eliCount, new(big.Int).Mul(mu, counts[d]))
+				sum.Add(sum, new(big.Int).Mul(mu, sums[d]))
+			}
+		}
+		if verbose {
+			fmt.Printf("Found %s invalid IDs in %s\n", eliCount, eli)
+		}
+		count.Add(count, eliCount)
+	}
+	return count, sum
+}
+
+// periodicBig counts and sums the IDs in the interval, whose bounds have the given width, that repeat a token of
+// length d.
+func periodicBig(eli *BigInterval, base, width, d int) (count, sum *big.Int) {
+	factor, lo, hi := tokenRange(eli, base, width, d)
+	if hi.Cmp(lo) < 0 {
+		return new(big.Int), new(big.Int)
+	}
+	count = new(big.Int).Sub(hi, lo)
+	count.Add(count, bigOne)
+	sum = new(big.Int).Add(lo, hi)
+	sum.Mul(sum, count)
+	sum.Rsh(sum, 1)
+	return count, sum.Mul(sum, factor)
+}
+
+// tokenRange returns the factor that turns tokens of length d into IDs of the given width, along with the smallest and
+// largest token whose ID is in the interval.
+func tokenRange(eli *BigInterval, base, width, d int) (factor, lo, hi *big.Int) {
+	factor = powBig(base, width)
+	factor.Sub(factor, bigOne)
+	factor.Quo(factor, powBig(base, d).Sub(powBig(base, d), bigOne))
+	lo = new(big.Int).Add(eli.A, factor)
+	lo.Sub(lo, bigOne)
+	lo.Quo(lo, factor)
+	if minToken := powBig(base, d-1); lo.Cmp(minToken) < 0 {
+		lo = minToken
+	}
+	hi = new(big.Int).Quo(eli.B, factor)
+	if maxToken := powBig(base, d).Sub(powBig(base, d), bigOne); hi.Cmp(maxToken) > 0 {
+		hi = maxToken
+	}
+	return factor, lo, hi
+}
+
+// enumerate yields the invalid IDs in the interval, whose bounds have the given width, in increasing order. It merges
+// the IDs for each token length, which are increasing by themselves, and drops the IDs found for several token
+// lengths. It returns false if yield did.
+func (q *Query) enumerate(eli *BigInterval, width int, yield func(*big.Int) bool) bool {
+	var h tokenHeap
+	for _, d := range q.tokenLens(width) {
+		factor, lo, hi := tokenRange(eli, q.Base, width, d)
+		if hi.Cmp(lo) >= 0 {
+			h = append(h, &tokenCursor{token: lo, hi: hi, factor: factor, id: new(big.Int).Mul(lo, factor)})
+		}
+	}
+	heap.Init(&h)
+	var last *big.Int
+	for len(h) > 0 {
+		c := h[0]
+		if last == nil || c.id.Cmp(last) != 0 {
+			last = new(big.Int).Set(c.id)
+			if !yield(new(big.Int).Set(c.id)) {
+				return false
+			}
+		}
+		if c.token.Cmp(c.hi) < 0 {
+			c.token.Add(c.token, bigOne)
+			c.id.Add(c.id, c.factor)
+			heap.Fix(&h, 0)
+		} else {
+			heap.Pop(&h)
+		}
+	}
+	return true
+}
+
+// tokenCursor walks through the IDs that repeat a token of some length.
+type tokenCursor struct {
+	token, hi, factor *big.Int
+	// id is the current token times the factor.
+	id *big.Int
+}
+
+type tokenHeap []*tokenCursor
+
+func (h tokenHeap) Len() int           { return len(h) }
+func (h tokenHeap) Less(i, j int) bool { return h[i].id.Cmp(h[j].id) < 0 }
+func (h tokenHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *tokenHeap) Push(x any)        { *h = append(*h, x.(*tokenCursor)) }
+func (h *tokenHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+func powBig(base, k int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(int64(base)), big.NewInt(int64(k)), nil)
+}
+
+// digits returns the number of digits of n in the base.
+func digits(n *big.Int, base int) int {
+	return len(n.Text(base))
+}
+
+// splitIntoEquilengthIntervalsBig splits the given interval into smallest number of consecutive intervals
+// such that the start and end of the interval have the same number of digits in the base.
+func splitIntoEquilengthIntervalsBig(in *BigInterval, base int) []*BigInterval {
+	la, lb := digits(in.A, base), digits(in.B, base)
+	if la == lb {
+		return []*BigInterval{in}
+	}
+	var res []*BigInterval
+	start := in.A
+	for l := la; l <= lb; l++ {
+		end := powBig(base, l)
+		end.Sub(end, bigOne)
+		if end.Cmp(in.B) > 0 {
+			end = in.B
+		}
+		res = append(res, &BigInterval{A: start, B: end})
+		start = new(big.Int).Add(end, bigOne)
+	}
+	return res
+}
@@ -2,196 +2,143 @@ package day02
 
 import (
 	"fmt"
+	"math"
+	"math/big"
+	"math/bits"
 	"os"
 	"strconv"
 	"strings"
 )
 
-func Round1(path string, verbose bool) (int64, error) {
-	intervals, err := LoadIntervals(path)
-	if err != nil {
-		return 0, err
-	}
-	var sum int64
-	for _, i := range intervals {
-		// Any number with an odd number of digits cannot be a repeated pattern.
-		low, lowLen, err := nextEvenDigits(i.A, false)
-		if err != nil {
-			return 0, fmt.Errorf(`could not "round up" %d to an even-digited number`, i.A)
-		}
-		high, highLen, err := nextEvenDigits(i.B, true)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, `could not "round down" %d to an even-digited number`, i.B)
+// Round1 sums the IDs that repeat a token exactly twice.
+func Round1(path string, verbose bool) (*big.Int, error) {
+	return Round1Query.Sum(path, verbose)
+}
+
+// Round2 sums the IDs that repeat a token at least twice.
+func Round2(path string, verbose bool) (*big.Int, error) {
+	return Round2Query.Sum(path, verbose)
+}
+
+// evalInt64 counts and sums the invalid IDs in the interval. The sum may overflow in between, but since it fits in the
+// end, it still comes out right.
+func (q *Query) evalInt64(interval *Interval, verbose bool) (count, sum int64) {
+	elis := splitIntoEquilengthIntervals(interval, q.Base)
+	if verbose {
+		fmt.Printf("Split %s into: %s\n", interval, elis)
+	}
+	for _, eli := range elis {
+		width := digits64(eli.A, q.Base)
+		periods := q.periods(width)
+		if len(periods) == 0 {
 			continue
 		}
-		if verbose {
-			fmt.Printf("Clamped %s down to %d-%d\n", i, low, high)
+		// First, find the IDs that repeat a token of length d, for every d that divides the width. Those include
+		// IDs whose token repeats itself, such as 2222 for d = 2.
+		counts, sums := make(map[int]int64), make(map[int]int64)
+		for _, d := range divisors(width) {
+			counts[d], sums[d] = periodic(eli, q.Base, width, d)
 		}
-		if high < low {
-			if verbose {
-				fmt.Println("Skipping empty interval")
+		// By Möbius inversion, the IDs whose shortest token has length p are those that repeat a token of length p,
+		// minus those that repeat a shorter one.
+		var eliCount int64
+		for _, p := range periods {
+			for _, d := range divisors(p) {
+				eliCount += int64(moebius(p/d)) * counts[d]
+				sum += int64(moebius(p/d)) * sums[d]
 			}
-			continue
-		}
-		if lowLen != highLen {
-			return 0, fmt.Errorf("cannot find invalid digits in intervals with different orders of magnitude: %s", i)
 		}
-		// Narrowed down to an interval of 2*n digits, we can now simply consider all n-prefixes in the
-		// interval and check if the corresponding repeated number is still in the interval.
-		var suffixSum int64
-		lowPrefix, lowSuffix := split(low)
-		highPrefix, highSuffix := split(high)
 		if verbose {
-			fmt.Printf("Decomposition: %d/%d - %d/%d\n", lowPrefix, lowSuffix, highPrefix, highSuffix)
-		}
-		// Check if lowPrefix, when repeated, is in the interval.
-		if lowPrefix >= lowSuffix && (lowPrefix < highPrefix || lowPrefix <= highSuffix) {
-			suffixSum += lowPrefix
-			if verbose {
-				fmt.Printf("Found invalid ID at the start of the interval: %d %d\n", lowPrefix, lowPrefix)
-			}
-		}
-		// Check if highPrefix, when repeated, is in the interval.
-		if highPrefix > lowPrefix && highPrefix <= highSuffix {
-			suffixSum += highPrefix
-			if verbose {
-				fmt.Printf("Found invalid ID at the end of the interval: %d %d\n", highPrefix, highPrefix)
-			}
+			fmt.Printf("Found %d invalid IDs in %s\n", eliCount, eli)
 		}
-		// Every prefix between lowPrefix and highPrefix yields a repeated pattern.
-		// We can use Gauss's famous trick to compute their sum.
-		if lowPrefix < highPrefix-1 {
-			suffixSum += highPrefix*(highPrefix-1)/2 - lowPrefix*(lowPrefix+1)/2
-			if verbose {
-				fmt.Printf("Found sum of invalid IDs in the middle of the intervals: %d %d\n", suffixSum, suffixSum)
-			}
-		}
-		// Because all suffixes have the same length, we can postpone and bundle the prefix calculation.
-		sum += suffixSum + exp(lowLen/2)*suffixSum
+		count += eliCount
 	}
-	return sum, nil
+	return count, sum
 }
 
-func nextEvenDigits(n int64, down bool) (res int64, length int, err error) {
-	if n <= 0 {
-		return 0, 0, fmt.Errorf("cannot make an even-digited number of %d", n)
-	}
-	s := fmt.Sprintf("%d", n)
-	if len(s)%2 == 0 {
-		return n, len(s), nil
+// periodic counts and sums the IDs in the interval, whose bounds have the given width, that repeat a token of length d.
+func periodic(eli *Interval, base, width, d int) (count, sum int64) {
+	// Such IDs are the tokens times 1 0..0 1 0..0 ... 1, e.g., 101010 for a token of length 2 and width 6.
+	factor := (pow(base, width) - 1) / (pow(base, d) - 1)
+	lo := max(pow(base, d-1), (eli.A+factor-1)/factor)
+	hi := min(pow(base, d)-1, eli.B/factor)
+	if hi < lo {
+		return 0, 0
+	}
+	count = hi - lo + 1
+	// Sum the tokens as an arithmetic series, halving the even factor first.
+	if count%2 == 0 {
+		return count, factor * (count / 2 * (lo + hi))
+	}
+	return count, factor * (count * ((lo + hi) / 2))
+}
+
+// divisors returns the divisors of n in increasing order.
+func divisors(n int) []int {
+	var res []int
+	for d := 1; d <= n; d++ {
+		if n%d == 0 {
+			res = append(res, d)
+		}
 	}
-	base := exp(len(s) - 1)
-	if down {
-		if base == 1 {
-			return 0, 0, fmt.Errorf("there is no even-digited number below %q", n)
+	return res
+}
+
+// moebius returns the Möbius function of n: 0 if a square divides n, and otherwise -1 or 1 for an odd or even number
+// of prime factors.
+func moebius(n int) int {
+	res := 1
+	for p := 2; p*p <= n; p++ {
+		if n%p != 0 {
+			continue
 		}
-		return base - 1, len(s) - 1, nil
+		n /= p
+		if n%p == 0 {
+			return 0
+		}
+		res = -res
+	}
+	if n > 1 {
+		res = -res
 	}
-	return base * 10, len(s) + 1, nil
+	return res
 }
 
-func exp(k int) int64 {
+// pow returns base^k, which must fit into an int64.
+func pow(base, k int) int64 {
 	if k < 0 {
-		panic(fmt.Sprintf("cannot compute 10^%d as an integer", k))
+		panic(fmt.Sprintf("cannot compute %d^%d as an integer", base, k))
 	}
 	var res int64 = 1
 	for i := 1; i <= k; i++ {
-		res *= 10
+		res *= int64(base)
 	}
 	return res
 }
 
-func split(n int64) (prefix, suffix int64) {
-	s := fmt.Sprintf("%d", n)
-	prefix, _ = strconv.ParseInt(s[:len(s)/2], 10, 64)
-	suffix, _ = strconv.ParseInt(s[len(s)/2:], 10, 64)
-	return prefix, suffix
-}
-
-func Round2(path string, verbose bool) (int64, error) {
-	intervals, err := LoadIntervals(path)
-	if err != nil {
-		return 0, err
-	}
-	var sum int64
-	for _, interval := range intervals {
-		elis := splitIntoEquilengthIntervals(interval)
-		if verbose {
-			fmt.Printf("Split %s into: %s\n", interval, elis)
-		}
-		for _, eli := range elis {
-			// Step 2: Iterate all possible token lengths (divisible by interval length).
-			width := len(fmt.Sprintf("%d", eli.A))
-			// Deduplicate patterns, such as 2222 that would be found at token lengths 1 and 2
-			invalidIDs := make(map[int64]struct{})
-			for tokenLen := 1; tokenLen <= width/2; tokenLen++ {
-				if width%tokenLen != 0 {
-					continue
-				}
-				// Step 3: Test the lowest possible invalid ID, e.g., starting from 123456 and token length 2, test 12 12 12.
-				tokenA := eli.A / exp(width-tokenLen)
-				patternA := createPattern(tokenA, tokenLen, width/tokenLen)
-				if patternA >= eli.A && patternA <= eli.B {
-					if verbose {
-						fmt.Printf("Adding start pattern in %s for token length %d: %d times %d = %d\n", eli, tokenLen, width/tokenLen, tokenA, patternA)
-					}
-					invalidIDs[patternA] = struct{}{}
-				}
-				// Step 4: Test the lowest possible invalid ID, e.g., ending at 153344 and token length 2, test 15 15 15
-				tokenB := eli.B / exp(width-tokenLen)
-				patternB := createPattern(tokenB, tokenLen, width/tokenLen)
-				if tokenA < tokenB && patternB >= eli.A && patternB <= eli.B {
-					if verbose {
-						fmt.Printf("End pattern in %s for token length %d: %d times %d = %d\n", eli, tokenLen, width/tokenLen, tokenB, patternB)
-					}
-					invalidIDs[patternB] = struct{}{}
-				}
-				// Step 5: Collect all possible invalid IDs in between the two above, e.g., 13 13 13 and 14 14 14.
-				if tokenB-tokenA > 1 {
-					if verbose {
-						fmt.Printf("Adding %d more inner patterns between %d and %d\n", tokenB-tokenA-1, patternA, patternB)
-					}
-					for innerToken := tokenA + 1; innerToken < tokenB; innerToken++ {
-						invalidIDs[createPattern(innerToken, tokenLen, width/tokenLen)] = struct{}{}
-					}
-				}
-			}
-			// Commit the IDs to the sum after the entire interval has been processed.
-			for invalidID := range invalidIDs {
-				sum += invalidID
-			}
-		}
+// digits64 returns the number of digits of n in the base.
+func digits64(n int64, base int) int {
+	res := 1
+	for n >= int64(base) {
+		n /= int64(base)
+		res++
 	}
-	return sum, nil
+	return res
 }
 
 // splitIntoEquilengthIntervals splits the given interval into smallest number of consecutive intervals
-// such that the start and end of the interval have the same number of digits.
-func splitIntoEquilengthIntervals(in *Interval) []*Interval {
-	sa := fmt.Sprintf("%d", in.A)
-	sb := fmt.Sprintf("%d", in.B)
-	if len(sa) == len(sb) {
+// such that the start and end of the interval have the same number of digits in the base.
+func splitIntoEquilengthIntervals(in *Interval, base int) []*Interval {
+	la, lb := digits64(in.A, base), digits64(in.B, base)
+	if la == lb {
 		return []*Interval{in}
 	}
 	var res []*Interval
 	start := in.A
-	end := exp(len(sa)) - 1
-	for start <= in.B {
+	for l := la; l <= lb; l++ {
+		end := min(pow(base, l)-1, in.B)
 		res = append(res, &Interval{A: start, B: end})
 		start = end + 1
-		end = start * 10
-		if end > in.B {
-			end = in.B
-		}
-	}
-	return res
-}
-
-func createPattern(token int64, tokenLen, reps int) int64 {
-	repFactor := exp(tokenLen)
-	var res int64
-	for i := 0; i < reps; i++ {
-		res = repFactor*res + token
 	}
 	return res
 }
@@ -222,15 +169,61 @@ func ParseInterval(code string) (*Interval, error) {
 	return &res, nil
 }
 
-func LoadIntervals(path string) ([]*Interval, error) {
-	var res []*Interval
+// BigInterval is an Interval of IDs of any size. It is empty if A > B.
+type BigInterval struct {
+	A, B *big.Int
+}
+
+func (s *BigInterval) String() string {
+	return fmt.Sprintf("%s-%s", s.A, s.B)
+}
+
+// int64 returns the interval for the int64 implementation, if it can handle it: The next power of the base above the
+// bounds must fit, and so must the sum of all IDs in the interval.
+func (s *BigInterval) int64(base int) (*Interval, bool) {
+	if !s.A.IsInt64() || !s.B.IsInt64() {
+		return nil, false
+	}
+	a, b := s.A.Int64(), s.B.Int64()
+	if a < 0 {
+		return nil, false
+	}
+	if hi, lo := bits.Mul64(uint64(pow(base, digits64(b, base)-1)), uint64(base)); hi != 0 || lo > math.MaxInt64 {
+		return nil, false
+	}
+	if b >= a {
+		hi, lo := bits.Mul64(uint64(b-a+1), uint64(b))
+		if hi != 0 || lo > math.MaxInt64 {
+			return nil, false
+		}
+	}
+	return &Interval{A: a, B: b}, true
+}
+
+func ParseBigInterval(code string) (*BigInterval, error) {
+	literals := strings.Split(code, "-")
+	if len(literals) != 2 {
+		return nil, fmt.Errorf("unexpected interval format: %q", code)
+	}
+	res := BigInterval{A: new(big.Int), B: new(big.Int)}
+	if _, ok := res.A.SetString(literals[0], 10); !ok {
+		return nil, fmt.Errorf("unexpected interval bound: %q", literals[0])
+	}
+	if _, ok := res.B.SetString(literals[1], 10); !ok {
+		return nil, fmt.Errorf("unexpected interval bound: %q", literals[1])
+	}
+	return &res, nil
+}
+
+func LoadIntervals(path string) ([]*BigInterval, error) {
+	var res []*BigInterval
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
 	codes := strings.Split(strings.TrimSpace(string(data)), ",")
 	for _, code := range codes {
-		i, err := ParseInterval(code) // Println will add back the final '\n'
+		i, err := ParseBigInterval(code) // Println will add back the final '\n'
 		if err != nil {
 			return nil, err
 		}
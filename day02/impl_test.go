@@ -1,7 +1,12 @@
 package day02
 
 import (
+	"math/big"
+	"math/rand/v2"
+	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -13,7 +18,7 @@ func TestResults(t *testing.T) {
 		if err != nil {
 			t.Fatalf("Unexpected error: %v", err)
 		}
-		if got != want {
+		if got.Cmp(big.NewInt(want)) != 0 {
 			t.Errorf("got %d, want %d", got, want)
 		}
 	})
@@ -23,8 +28,262 @@ func TestResults(t *testing.T) {
 		if err != nil {
 			t.Fatalf("Unexpected error: %v", err)
 		}
-		if got != want {
+		if got.Cmp(big.NewInt(want)) != 0 {
 			t.Errorf("got %d, want %d", got, want)
 		}
 	})
 }
+
+// repeats tells whether the ID, written in some base, consists of a token repeated as often as the query allows.
+func repeats(id string, q *Query) bool {
+	for tokenLen := 1; tokenLen <= len(id); tokenLen++ {
+		reps := len(id) / tokenLen
+		if len(id)%tokenLen != 0 || reps < q.MinReps || q.MaxReps != 0 && reps > q.MaxReps {
+			continue
+		}
+		if strings.Repeat(id[:tokenLen], reps) == id {
+			return true
+		}
+	}
+	return false
+}
+
+// bruteForce lists the invalid IDs in the interval by checking every single one.
+func bruteForce(i *BigInterval, q *Query) []*big.Int {
+	var res []*big.Int
+	for id := new(big.Int).Set(i.A); id.Cmp(i.B) <= 0; id.Add(id, bigOne) {
+		if repeats(id.Text(q.Base), q) {
+			res = append(res, new(big.Int).Set(id))
+		}
+	}
+	return res
+}
+
+func total(ids []*big.Int) *big.Int {
+	res := new(big.Int)
+	for _, id := range ids {
+		res.Add(res, id)
+	}
+	return res
+}
+
+// randomInterval returns an interval whose bounds may have different numbers of digits.
+func randomInterval(rng *rand.Rand, maxDigits int) *BigInterval {
+	a := rng.Int64N(pow(10, 1+rng.IntN(maxDigits)))
+	b := a + rng.Int64N(2000)
+	return &BigInterval{A: big.NewInt(a), B: big.NewInt(b)}
+}
+
+func randomQuery(rng *rand.Rand) *Query {
+	q := &Query{Base: []int{2, 3, 7, 10, 16, 36}[rng.IntN(6)], MinReps: 2 + rng.IntN(3)}
+	switch rng.IntN(3) {
+	case 1:
+		q.MaxReps = q.MinReps
+	case 2:
+		q.MaxReps = q.MinReps + 1
+	}
+	return q
+}
+
+func TestQuery(t *testing.T) {
+	rng := rand.New(rand.NewPCG(2, 2))
+	intervals := []*BigInterval{
+		{A: big.NewInt(1), B: big.NewInt(100000)},
+		{A: big.NewInt(11111110), B: big.NewInt(11113000)},
+		{A: big.NewInt(121212120000), B: big.NewInt(121212122000)},
+		{A: big.NewInt(99999999), B: big.NewInt(100001000)},
+	}
+	for range 200 {
+		intervals = append(intervals, randomInterval(rng, 12))
+	}
+	for k, i := range intervals {
+		q := Round2Query
+		if k >= 4 {
+			q = randomQuery(rng)
+		}
+		want := bruteForce(i, q)
+		wantCount, wantSum := big.NewInt(int64(len(want))), total(want)
+		i64, ok := i.int64(q.Base)
+		if !ok {
+			t.Fatalf("%s: expected the int64 implementation", i)
+		}
+		if count, sum := q.evalInt64(i64, false); big.NewInt(count).Cmp(wantCount) != 0 || big.NewInt(sum).Cmp(wantSum) != 0 {
+			t.Errorf("%s, %s: got %d IDs with sum %d with int64, want %d with sum %d", i, q, count, sum, wantCount, wantSum)
+		}
+		if count, sum := q.evalBig(i, false); count.Cmp(wantCount) != 0 || sum.Cmp(wantSum) != 0 {
+			t.Errorf("%s, %s: got %d IDs with sum %d with math/big, want %d with sum %d", i, q, count, sum, wantCount, wantSum)
+		}
+		var got []*big.Int
+		for _, eli := range splitIntoEquilengthIntervalsBig(i, q.Base) {
+			q.enumerate(eli, digits(eli.A, q.Base), func(id *big.Int) bool {
+				got = append(got, id)
+				return true
+			})
+		}
+		if !slices.EqualFunc(got, want, func(a, b *big.Int) bool { return a.Cmp(b) == 0 }) {
+			t.Errorf("%s, %s: got %v, want %v", i, q, got, want)
+		}
+	}
+}
+
+func TestBig(t *testing.T) {
+	rng := rand.New(rand.NewPCG(2, 2))
+	// Both implementations agree where they overlap.
+	for range 1000 {
+		q := randomQuery(rng)
+		digits := 2 + rng.IntN(8)
+		a := pow(10, digits-1) + rng.Int64N(pow(10, digits)-pow(10, digits-1))
+		b := a + rng.Int64N(pow(10, digits/2+1))
+		i := &BigInterval{A: big.NewInt(a), B: big.NewInt(b)}
+		i64, ok := i.int64(q.Base)
+		if !ok {
+			t.Fatalf("%s: expected the int64 implementation", i)
+		}
+		wantCount, wantSum := q.evalInt64(i64, false)
+		if count, sum := q.evalBig(i, false); count.Cmp(big.NewInt(wantCount)) != 0 || sum.Cmp(big.NewInt(wantSum)) != 0 {
+			t.Errorf("%s, %s: got %d IDs with sum %d, want %d with sum %d", i, q, count, sum, wantCount, wantSum)
+		}
+	}
+	// Beyond int64, the results match a brute force search.
+	for _, code := range []string{
+		"12345678901234567890-12345678901234567899",
+		"9223372036854775800-9223372036854775900",
+		"1212121212121212121100-1212121212121212121300",
+		"99999999999999999999999999999980-99999999999999999999999999999999",
+	} {
+		i, err := ParseBigInterval(code)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		for _, q := range []*Query{Round1Query, Round2Query} {
+			if _, ok := i.int64(q.Base); ok {
+				t.Errorf("%s: got the int64 implementation", i)
+			}
+			want := bruteForce(i, q)
+			if count, sum := q.evalBig(i, false); count.Cmp(big.NewInt(int64(len(want)))) != 0 || sum.Cmp(total(want)) != 0 {
+				t.Errorf("%s, %s: got %d IDs with sum %d, want %d with sum %d", i, q, count, sum, len(want), total(want))
+			}
+		}
+	}
+}
+
+func TestInt64(t *testing.T) {
+	for _, tc := range []struct {
+		code string
+		base int
+		want bool
+	}{
+		{"11-22", 10, true},
+		{"999999999999999991-999999999999999999", 10, true},
+		{"999999999999999990-999999999999999999", 10, false},
+		{"1000000000000000000-1000000000000000001", 10, false},
+		{"100000000000-999999999999", 10, false},
+		{"9223372036854775807-9223372036854775808", 10, false},
+		// 2^62 has 63 binary digits, but 2^63 does not fit.
+		{"4611686018427387904-4611686018427387904", 2, false},
+		{"4611686018427387903-4611686018427387903", 2, true},
+	} {
+		i, err := ParseBigInterval(tc.code)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, got := i.int64(tc.base); got != tc.want {
+			t.Errorf("%s in base %d: got %t, want %t", tc.code, tc.base, got, tc.want)
+		}
+	}
+}
+
+func TestList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "input.txt")
+	if err := os.WriteFile(path, []byte("95-1012,222220-222224,1-30\n"), 0o644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, tc := range []struct {
+		q    *Query
+		want []string
+	}{
+		{Round1Query, []string{"99", "1010", "222222", "11", "22"}},
+		{Round2Query, []string{"99", "111", "222", "333", "444", "555", "666", "777", "888", "999", "1010", "222222", "11", "22"}},
+		// In binary, 3 is 11, 7 is 111, 10 is 1010 and 15 is 1111, but 21 is 10101.
+		{&Query{Base: 2, MinReps: 2}, nil},
+	} {
+		if tc.want == nil {
+			intervals, err := LoadIntervals(path)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			for _, i := range intervals {
+				for _, id := range bruteForce(i, tc.q) {
+					tc.want = append(tc.want, id.String())
+				}
+			}
+		}
+		ids, err := tc.q.List(path)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		var got []string
+		for id := range ids {
+			got = append(got, id.String())
+		}
+		if !slices.Equal(got, tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.q, got, tc.want)
+		}
+	}
+	if _, err := (&Query{Base: 37, MinReps: 2}).List(path); err == nil {
+		t.Error("got no error for base 37")
+	}
+}
+
+// TestAwkwardRanges runs both rounds on intervals that start at 0 or 1, end on powers of 10, span several numbers of
+// digits, are reversed or consist of a single number.
+func TestAwkwardRanges(t *testing.T) {
+	codes := []string{
+		"0-0", "0-9", "0-11", "1-1", "1-10", "9-11", "10-10", "11-11", "22-11", "1000-10",
+		"99-101", "100-100", "1010-1010", "123123-123123", "1-1000", "999-10000", "99999-100001", "1-100000",
+		"9999999999-10000000000", "999999999999999990-1000000000000000010", "9223372036854775000-9223372036854775807",
+	}
+	path := filepath.Join(t.TempDir(), "input.txt")
+	if err := os.WriteFile(path, []byte(strings.Join(codes, ",")+"\n"), 0o644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, tc := range []struct {
+		name  string
+		round func(string, bool) (*big.Int, error)
+		q     *Query
+	}{
+		{"Round 1", Round1, Round1Query},
+		{"Round 2", Round2, Round2Query},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			want := new(big.Int)
+			for _, code := range codes {
+				i, err := ParseBigInterval(code)
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				ids := bruteForce(i, tc.q)
+				want.Add(want, total(ids))
+				if _, sum, err := tc.q.Eval([]*BigInterval{i}, false); err != nil || sum.Cmp(total(ids)) != 0 {
+					t.Errorf("%s: got %d, %v, want %d", code, sum, err, total(ids))
+				}
+			}
+			got, err := tc.round(path, false)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got.Cmp(want) != 0 {
+				t.Errorf("got %d, want %d", got, want)
+			}
+		})
+	}
+	// From 1 to 10^20, round 1 finds 9*10^(k-1) IDs with 2k digits for k from 1 to 10.
+	i, err := ParseBigInterval("1-100000000000000000000")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	const want = 9999999999
+	if count, _, err := Round1Query.Eval([]*BigInterval{i}, false); err != nil || count.Cmp(big.NewInt(want)) != 0 {
+		t.Errorf("%s: got %d, %v, want %d", i, count, err, want)
+	}
+}
@@ -0,0 +1,196 @@
+package day02
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"iter"
+	"math/big"
+)
+
+// Query describes which IDs are invalid: those that consist of a token repeated a number of times, when written in the
+// base. The intervals and the results are decimal, regardless of the base.
+type Query struct {
+	// Base is between 2 and 36.
+	Base int
+	// MinReps and MaxReps bound how often the token repeats. MinReps is at least 2, and MaxReps 0 means no bound.
+	MinReps, MaxReps int
+}
+
+var (
+	// Round1Query finds the IDs that repeat a token exactly twice.
+	Round1Query = &Query{Base: 10, MinReps: 2, MaxReps: 2}
+	// Round2Query finds the IDs that repeat a token at least twice.
+	Round2Query = &Query{Base: 10, MinReps: 2}
+)
+
+func (q *Query) String() string {
+	if q.MaxReps == 0 {
+		return fmt.Sprintf("base %d, at least %d repetitions", q.Base, q.MinReps)
+	}
+	return fmt.Sprintf("base %d, %d to %d repetitions", q.Base, q.MinReps, q.MaxReps)
+}
+
+// Validate checks that the parameters are in range.
+func (q *Query) Validate() error {
+	if q.Base < 2 || q.Base > 36 {
+		return fmt.Errorf("expected a base between 2 and 36, got %d", q.Base)
+	}
+	if q.MinReps < 2 {
+		return fmt.Errorf("expected at least 2 repetitions, got %d", q.MinReps)
+	}
+	if q.MaxReps != 0 && q.MaxReps < q.MinReps {
+		return fmt.Errorf("expected at most %d repetitions to be at least %d", q.MaxReps, q.MinReps)
+	}
+	return nil
+}
+
+// tokenLens returns the lengths of the tokens that IDs of the width may repeat.
+func (q *Query) tokenLens(width int) []int {
+	var res []int
+	for _, d := range divisors(width) {
+		if reps := width / d; reps >= q.MinReps && (q.MaxReps == 0 || reps <= q.MaxReps) {
+			res = append(res, d)
+		}
+	}
+	return res
+}
+
+// periods returns the lengths of the shortest tokens of invalid IDs of the width. An ID with the shortest token p
+// repeats every token whose length is a multiple of p and divides the width, so it is invalid if any of those is
+// in tokenLens.
+func (q *Query) periods(width int) []int {
+	tokenLens := q.tokenLens(width)
+	var res []int
+	for _, p := range divisors(width) {
+		for _, d := range tokenLens {
+			if d%p == 0 {
+				res = append(res, p)
+				break
+			}
+		}
+	}
+	return res
+}
+
+// Eval counts and sums the invalid IDs in the intervals. Intervals that the int64 implementation can handle do not need
+// math/big.
+func (q *Query) Eval(intervals []*BigInterval, verbose bool) (count, sum *big.Int, err error) {
+	if err := q.Validate(); err != nil {
+		return nil, nil, err
+	}
+	count, sum = new(big.Int), new(big.Int)
+	for _, i := range intervals {
+		if i64, ok := i.int64(q.Base); ok {
+			c, s := q.evalInt64(i64, verbose)
+			count.Add(count, big.NewInt(c))
+			sum.Add(sum, big.NewInt(s))
+			continue
+		}
+		if verbose {
+			fmt.Printf("Falling back to math/big for %s\n", i)
+		}
+		c, s := q.evalBig(i, verbose)
+		count.Add(count, c)
+		sum.Add(sum, s)
+	}
+	return count, sum, nil
+}
+
+// Count counts the invalid IDs in the intervals of the file.
+func (q *Query) Count(path string, verbose bool) (*big.Int, error) {
+	intervals, err := LoadIntervals(path)
+	if err != nil {
+		return nil, err
+	}
+	count, _, err := q.Eval(intervals, verbose)
+	return count, err
+}
+
+// Sum sums the invalid IDs in the intervals of the file.
+func (q *Query) Sum(path string, verbose bool) (*big.Int, error) {
+	intervals, err := LoadIntervals(path)
+	if err != nil {
+		return nil, err
+	}
+	_, sum, err := q.Eval(intervals, verbose)
+	return sum, err
+}
+
+// List streams the invalid IDs in the intervals of the file, in increasing order within each interval.
+func (q *Query) List(path string) (iter.Seq[*big.Int], error) {
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+	intervals, err := LoadIntervals(path)
+	if err != nil {
+		return nil, err
+	}
+	return func(yield func(*big.Int) bool) {
+		for _, interval := range intervals {
+			for _, eli := range splitIntoEquilengthIntervalsBig(interval, q.Base) {
+				width := digits(eli.A, q.Base)
+				if !q.enumerate(eli, width, yield) {
+					return
+				}
+			}
+		}
+	}, nil
+}
+
+// Aggregate is what a query returns about the invalid IDs.
+type Aggregate int
+
+const (
+	Sum Aggregate = iota
+	Count
+	List
+)
+
+var aggregateNames = [...]string{
+	Sum:   "sum",
+	Count: "count",
+	List:  "list",
+}
+
+func ParseAggregate(name string) (Aggregate, error) {
+	for a, n := range aggregateNames {
+		if n == name {
+			return Aggregate(a), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown aggregate %q", name)
+}
+
+func (a Aggregate) String() string {
+	return aggregateNames[a]
+}
+
+// Run runs the query on the intervals of the file and writes the aggregate to w, with one ID per line for List.
+func (q *Query) Run(path string, agg Aggregate, w io.Writer, verbose bool) error {
+	var res *big.Int
+	var err error
+	switch agg {
+	case Sum:
+		res, err = q.Sum(path, verbose)
+	case Count:
+		res, err = q.Count(path, verbose)
+	case List:
+		ids, err := q.List(path)
+		if err != nil {
+			return err
+		}
+		bw := bufio.NewWriter(w)
+		for id := range ids {
+			fmt.Fprintln(bw, id)
+		}
+		return bw.Flush()
+	default:
+		return fmt.Errorf("unknown aggregate %d", agg)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, res)
+	return err
+}
@@ -3,80 +3,111 @@ package day03
 import (
 	"bufio"
 	"fmt"
+	"math/big"
 	"os"
 	"strconv"
 	"strings"
 )
 
-func Round1(path string, verbose bool) (int, error) {
-	banks, err := LoadBatteryBanks(path)
-	if err != nil {
-		return 0, err
-	}
-	var joltage int
-	for _, b := range banks {
-		j := b.Joltage()
-		if verbose {
-			fmt.Printf("Joltage for %v: %d\n", b, j)
-		}
-		joltage += j
-	}
-	return joltage, nil
+// Round1 sums the largest joltages of two batteries per bank.
+func Round1(path string, verbose bool) (*big.Int, error) {
+	return Sum(path, 2, verbose)
 }
 
-func Round2(path string, verbose bool) (int64, error) {
-	const batteries = 12
+// Round2 sums the largest joltages of twelve batteries per bank.
+func Round2(path string, verbose bool) (*big.Int, error) {
+	return Sum(path, 12, verbose)
+}
+
+// Sum sums the largest joltages of k batteries per bank.
+func Sum(path string, k int, verbose bool) (*big.Int, error) {
 	banks, err := LoadBatteryBanks(path)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
-	var joltage int64
+	res := new(big.Int)
 	for _, b := range banks {
-		j := b.JoltageN(batteries, verbose)
+		s, err := b.Select(k)
+		if err != nil {
+			return nil, err
+		}
 		if verbose {
-			fmt.Printf("Joltage for %v: %d\n", b, j)
+			fmt.Printf("Joltage for %s: %s\n", s.Explain(), s)
 		}
-		joltage += j
+		res.Add(res, s.Joltage())
 	}
-	return joltage, nil
+	return res, nil
 }
 
 type BatteryBank []int
 
-func (b BatteryBank) Joltage() int {
-	var pos1, pos2 int
-	for i := 1; i < len(b)-1; i++ {
-		if b[pos1] < b[i] {
-			pos1 = i
-		}
+func (b BatteryBank) String() string {
+	var sb strings.Builder
+	for _, d := range b {
+		sb.WriteByte(byte('0' + d))
 	}
-	pos2 = pos1 + 1
-	for i := pos1 + 2; i < len(b); i++ {
-		if b[pos2] < b[i] {
-			pos2 = i
+	return sb.String()
+}
+
+// Selection is a choice of batteries from a bank, in order.
+type Selection struct {
+	Bank BatteryBank
+	// Indices are the positions of the chosen batteries in the bank, in increasing order.
+	Indices []int
+}
+
+// Select chooses the k batteries whose digits form the largest number, in a single pass over the bank.
+//
+// It keeps the chosen batteries on a stack whose digits never increase: A battery replaces the smaller ones on top of
+// the stack, as long as enough batteries remain after it to choose k in total.
+func (b BatteryBank) Select(k int) (*Selection, error) {
+	if k < 1 || k > len(b) {
+		return nil, fmt.Errorf("cannot choose %d of %d batteries", k, len(b))
+	}
+	drops := len(b) - k
+	stack := make([]int, 0, len(b))
+	for i, d := range b {
+		for drops > 0 && len(stack) > 0 && b[stack[len(stack)-1]] < d {
+			stack = stack[:len(stack)-1]
+			drops--
 		}
+		stack = append(stack, i)
 	}
-	return 10*b[pos1] + b[pos2]
+	return &Selection{Bank: b, Indices: stack[:k]}, nil
 }
 
-func (b BatteryBank) JoltageN(n int, verbose bool) int64 {
-	var start int
-	var res int64
-	for k := 0; k < n; k++ {
-		pos := start
-		end := len(b) - (n - k - 1)
-		for i := start + 1; i < end; i++ {
-			if b[pos] < b[i] {
-				pos = i
-			}
+// Joltage returns the number that the digits of the chosen batteries form.
+func (s *Selection) Joltage() *big.Int {
+	// Up to 18 digits fit into an int64.
+	if len(s.Indices) <= 18 {
+		var res int64
+		for _, i := range s.Indices {
+			res = 10*res + int64(s.Bank[i])
 		}
-		fmt.Printf("Chose digit %d at %d\n", b[pos], pos)
-		res = 10*res + int64(b[pos])
-		start = pos + 1
+		return big.NewInt(res)
 	}
+	res, _ := new(big.Int).SetString(s.String(), 10)
 	return res
 }
 
+// String returns the digits of the chosen batteries.
+func (s *Selection) String() string {
+	var sb strings.Builder
+	for _, i := range s.Indices {
+		sb.WriteByte(byte('0' + s.Bank[i]))
+	}
+	return sb.String()
+}
+
+// Explain returns the bank with a dot in place of every battery that was not chosen.
+func (s *Selection) Explain() string {
+	res := []byte(strings.Repeat(".", len(s.Bank)))
+	for _, i := range s.Indices {
+		res[i] = byte('0' + s.Bank[i])
+	}
+	return string(res)
+}
+
 func LoadBatteryBanks(path string) ([]BatteryBank, error) {
 	var res []BatteryBank
 	file, err := os.Open(path)
@@ -1,7 +1,10 @@
 package day03
 
 import (
+	"math/big"
+	"math/rand/v2"
 	"path/filepath"
+	"slices"
 	"testing"
 )
 
@@ -13,7 +16,7 @@ func TestResults(t *testing.T) {
 		if err != nil {
 			t.Fatalf("Unexpected error: %v", err)
 		}
-		if got != want {
+		if got.Cmp(big.NewInt(want)) != 0 {
 			t.Errorf("got %d, want %d", got, want)
 		}
 	})
@@ -23,8 +26,84 @@ func TestResults(t *testing.T) {
 		if err != nil {
 			t.Fatalf("Unexpected error: %v", err)
 		}
-		if got != want {
+		if got.Cmp(big.NewInt(want)) != 0 {
 			t.Errorf("got %d, want %d", got, want)
 		}
 	})
 }
+
+func TestSelect(t *testing.T) {
+	for _, tc := range []struct {
+		bank    string
+		k       int
+		want    string
+		indices []int
+	}{
+		{"987654321111111", 2, "98", []int{0, 1}},
+		{"811111111111119", 2, "89", []int{0, 14}},
+		{"234234234234278", 2, "78", []int{13, 14}},
+		{"818181911112111", 12, "888911112111", []int{0, 2, 4, 6, 7, 8, 9, 10, 11, 12, 13, 14}},
+		{"11111", 5, "11111", []int{0, 1, 2, 3, 4}},
+		{"5", 1, "5", []int{0}},
+		{"1234567890123456789012345", 20, "67890123456789012345", []int{5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23, 24}},
+	} {
+		s, err := parseBank(tc.bank).Select(tc.k)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if s.String() != tc.want || !slices.Equal(s.Indices, tc.indices) {
+			t.Errorf("%s, %d: got %s at %v, want %s at %v", tc.bank, tc.k, s, s.Indices, tc.want, tc.indices)
+		}
+		if want, _ := new(big.Int).SetString(tc.want, 10); s.Joltage().Cmp(want) != 0 {
+			t.Errorf("%s, %d: got joltage %d, want %d", tc.bank, tc.k, s.Joltage(), want)
+		}
+	}
+	for _, k := range []int{0, 4} {
+		if _, err := parseBank("123").Select(k); err == nil {
+			t.Errorf("got no error for %d of 3 batteries", k)
+		}
+	}
+}
+
+// greedy chooses k batteries by scanning for the first largest digit that leaves enough batteries after it.
+func greedy(b BatteryBank, k int) string {
+	var res []byte
+	start := 0
+	for n := k; n > 0; n-- {
+		pos := start
+		for i := start + 1; i <= len(b)-n; i++ {
+			if b[pos] < b[i] {
+				pos = i
+			}
+		}
+		res = append(res, byte('0'+b[pos]))
+		start = pos + 1
+	}
+	return string(res)
+}
+
+func TestSelectRandom(t *testing.T) {
+	rng := rand.New(rand.NewPCG(3, 3))
+	for range 1000 {
+		b := make(BatteryBank, 1+rng.IntN(40))
+		for i := range b {
+			b[i] = 1 + rng.IntN(3+rng.IntN(7))
+		}
+		k := 1 + rng.IntN(len(b))
+		s, err := b.Select(k)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if want := greedy(b, k); s.String() != want {
+			t.Errorf("%s, %d: got %s, want %s", b, k, s, want)
+		}
+	}
+}
+
+func parseBank(enc string) BatteryBank {
+	var res BatteryBank
+	for _, r := range enc {
+		res = append(res, int(r-'0'))
+	}
+	return res
+}
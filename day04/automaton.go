@@ -0,0 +1,227 @@
+package day04
+
+import (
+	"container/heap"
+	"fmt"
+)
+
+// Neighborhood is the shape of the cells around a roll that count as its neighbors.
+type Neighborhood int
+
+const (
+	// Moore has the eight cells around the roll, including the diagonals.
+	Moore Neighborhood = iota
+	// VonNeumann has the four cells that share an edge with the roll.
+	VonNeumann
+)
+
+var neighborhoodNames = [...]string{
+	Moore:      "moore",
+	VonNeumann: "von-neumann",
+}
+
+func ParseNeighborhood(name string) (Neighborhood, error) {
+	for n, s := range neighborhoodNames {
+		if s == name {
+			return Neighborhood(n), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown neighborhood %q", name)
+}
+
+func (n Neighborhood) String() string {
+	return neighborhoodNames[n]
+}
+
+func (n Neighborhood) offsets() []*Offset {
+	if n == VonNeumann {
+		return []*Offset{{0, -1}, {-1, 0}, {1, 0}, {0, 1}}
+	}
+	return neighborMask
+}
+
+// Rule decides which rolls the forklifts can remove.
+type Rule struct {
+	// Threshold is the number of neighboring rolls from which on a roll is stuck.
+	Threshold    int
+	Neighborhood Neighborhood
+	// InPlace removes rolls as soon as they are found, in reading order, so that rolls further down in the same wave
+	// see the removal. Otherwise, all rolls of a wave are found first and removed together. Either way, the same rolls
+	// are removed in the end, only the waves differ.
+	InPlace bool
+}
+
+// DefaultRule is the rule from the puzzle.
+var DefaultRule = Rule{Threshold: 4, Neighborhood: Moore}
+
+// Automaton removes rolls from a floor plan in waves. It keeps the number of neighboring rolls for each cell, so that
+// a wave only needs to look at the neighbors of rolls removed in the previous one.
+type Automaton struct {
+	width, height int
+	rule          Rule
+	offsets       []*Offset
+	rolls         []bool
+	// neighbors is the number of neighboring rolls of each cell.
+	neighbors []int
+	// next holds the cells to look at in the next wave, and queued marks the cells that are in next or in the wave
+	// that is running.
+	next   []int
+	queued []bool
+}
+
+// NewAutomaton prepares the removal of rolls from a copy of the plan.
+func NewAutomaton(plan FloorPlan, rule Rule) (*Automaton, error) {
+	if rule.Threshold < 0 {
+		return nil, fmt.Errorf("expected a non-negative threshold, got %d", rule.Threshold)
+	}
+	if rule.Neighborhood < 0 || int(rule.Neighborhood) >= len(neighborhoodNames) {
+		return nil, fmt.Errorf("unknown neighborhood %d", rule.Neighborhood)
+	}
+	a := &Automaton{height: len(plan), rule: rule, offsets: rule.Neighborhood.offsets()}
+	if len(plan) > 0 {
+		a.width = len(plan[0])
+	}
+	a.rolls = make([]bool, a.width*a.height)
+	a.neighbors = make([]int, len(a.rolls))
+	a.queued = make([]bool, len(a.rolls))
+	for y, row := range plan {
+		for x, tile := range row {
+			a.rolls[y*a.width+x] = tile == Tile_PaperRoll
+		}
+	}
+	for c, roll := range a.rolls {
+		if !roll {
+			continue
+		}
+		for _, n := range a.neighborsOf(c) {
+			a.neighbors[n]++
+		}
+		a.next = append(a.next, c)
+		a.queued[c] = true
+	}
+	return a, nil
+}
+
+// neighborsOf returns the cells in the neighborhood of the cell that are on the plan.
+func (a *Automaton) neighborsOf(c int) []int {
+	x, y := c%a.width, c/a.width
+	res := make([]int, 0, len(a.offsets))
+	for _, o := range a.offsets {
+		x2, y2 := x+o.X, y+o.Y
+		if x2 < 0 || x2 >= a.width || y2 < 0 || y2 >= a.height {
+			continue
+		}
+		res = append(res, y2*a.width+x2)
+	}
+	return res
+}
+
+func (a *Automaton) removable(c int) bool {
+	return a.rolls[c] && a.neighbors[c] < a.rule.Threshold
+}
+
+// remove removes the roll and calls visit for each neighboring roll.
+func (a *Automaton) remove(c int, visit func(n int)) {
+	a.rolls[c] = false
+	for _, n := range a.neighborsOf(c) {
+		a.neighbors[n]--
+		if a.rolls[n] {
+			visit(n)
+		}
+	}
+}
+
+// Wave removes the next wave of rolls and returns how many it removed, which is 0 once no more rolls can be removed.
+func (a *Automaton) Wave() int {
+	if a.rule.InPlace {
+		return a.waveInPlace()
+	}
+	return a.waveSync()
+}
+
+func (a *Automaton) waveSync() int {
+	var removed []int
+	for _, c := range a.next {
+		a.queued[c] = false
+		if a.removable(c) {
+			removed = append(removed, c)
+		}
+	}
+	a.next = a.next[:0]
+	for _, c := range removed {
+		a.remove(c, func(n int) {
+			if !a.queued[n] {
+				a.queued[n] = true
+				a.next = append(a.next, n)
+			}
+		})
+	}
+	return len(removed)
+}
+
+func (a *Automaton) waveInPlace() int {
+	// Rolls further down in reading order join the running wave, all others wait for the next.
+	wave := cellHeap(a.next)
+	heap.Init(&wave)
+	a.next = nil
+	var removed int
+	for len(wave) > 0 {
+		c := heap.Pop(&wave).(int)
+		a.queued[c] = false
+		if !a.removable(c) {
+			continue
+		}
+		removed++
+		a.remove(c, func(n int) {
+			if a.queued[n] {
+				return
+			}
+			a.queued[n] = true
+			if n > c {
+				heap.Push(&wave, n)
+			} else {
+				a.next = append(a.next, n)
+			}
+		})
+	}
+	return removed
+}
+
+// Run removes waves of rolls until no more can be removed and returns the number of rolls removed per wave.
+func (a *Automaton) Run() []int {
+	var res []int
+	for {
+		removed := a.Wave()
+		if removed == 0 {
+			return res
+		}
+		res = append(res, removed)
+	}
+}
+
+// Plan returns the floor plan with the rolls that remain.
+func (a *Automaton) Plan() FloorPlan {
+	res := make(FloorPlan, a.height)
+	for y := range res {
+		res[y] = make([]Tile, a.width)
+		for x := range res[y] {
+			if a.rolls[y*a.width+x] {
+				res[y][x] = Tile_PaperRoll
+			}
+		}
+	}
+	return res
+}
+
+type cellHeap []int
+
+func (h cellHeap) Len() int           { return len(h) }
+func (h cellHeap) Less(i, j int) bool { return h[i] < h[j] }
+func (h cellHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *cellHeap) Push(x any)        { *h = append(*h, x.(int)) }
+func (h *cellHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
@@ -6,69 +6,37 @@ import (
 	"os"
 )
 
-func Round1(path string, verbose bool) (int, error) {
-	plan, err := LoadFloorPlan(path)
+// Round1 counts the rolls that the first wave removes.
+func Round1(path string, rule Rule, verbose bool) (int, error) {
+	a, err := loadAutomaton(path, rule)
 	if err != nil {
 		return 0, err
 	}
-	var clearPaperRolls int
-	for y, row := range plan {
-		for x, tile := range row {
-			if tile != Tile_PaperRoll {
-				continue
-			}
-			var neighbors int
-			for _, offset := range neighborMask {
-				x2, y2 := x+offset.X, y+offset.Y
-				if x2 < 0 || x2 >= len(row) || y2 < 0 || y2 >= len(plan) {
-					continue
-				}
-				if plan[y2][x2] != Tile_Clear {
-					neighbors++
-				}
-			}
-			if neighbors < 4 {
-				clearPaperRolls++
-			}
-		}
-	}
-	return clearPaperRolls, nil
+	return a.Wave(), nil
 }
 
-func Round2(path string, verbose bool) (int, error) {
-	plan, err := LoadFloorPlan(path)
+// Round2 counts the rolls that all waves remove together.
+func Round2(path string, rule Rule, verbose bool) (int, error) {
+	a, err := loadAutomaton(path, rule)
 	if err != nil {
 		return 0, err
 	}
-	var clearPaperRolls int
-	for {
-		prevClearPaperRolls := clearPaperRolls
-		for y, row := range plan {
-			for x, tile := range row {
-				if tile != Tile_PaperRoll {
-					continue
-				}
-				var neighbors int
-				for _, offset := range neighborMask {
-					x2, y2 := x+offset.X, y+offset.Y
-					if x2 < 0 || x2 >= len(row) || y2 < 0 || y2 >= len(plan) {
-						continue
-					}
-					if plan[y2][x2] != Tile_Clear {
-						neighbors++
-					}
-				}
-				if neighbors < 4 {
-					clearPaperRolls++
-					plan[y][x] = Tile_Clear
-				}
-			}
-		}
-		if prevClearPaperRolls == clearPaperRolls {
-			break
+	var res int
+	for i, removed := range a.Run() {
+		if verbose {
+			fmt.Printf("Wave %d removed %d rolls\n", i+1, removed)
 		}
+		res += removed
+	}
+	return res, nil
+}
+
+func loadAutomaton(path string, rule Rule) (*Automaton, error) {
+	plan, err := LoadFloorPlan(path)
+	if err != nil {
+		return nil, err
 	}
-	return clearPaperRolls, nil
+	return NewAutomaton(plan, rule)
 }
 
 type Tile int
@@ -1,7 +1,9 @@
 package day04
 
 import (
+	"math/rand/v2"
 	"path/filepath"
+	"slices"
 	"testing"
 )
 
@@ -9,7 +11,7 @@ func TestResults(t *testing.T) {
 	testFilePath := filepath.Join("testdata", "example.txt")
 	t.Run("Round 1", func(t *testing.T) {
 		const want = 13
-		got, err := Round1(testFilePath, false)
+		got, err := Round1(testFilePath, DefaultRule, false)
 		if err != nil {
 			t.Fatalf("Unexpected error: %v", err)
 		}
@@ -19,7 +21,7 @@ func TestResults(t *testing.T) {
 	})
 	t.Run("Round 2", func(t *testing.T) {
 		const want = 43
-		got, err := Round2(testFilePath, true)
+		got, err := Round2(testFilePath, DefaultRule, true)
 		if err != nil {
 			t.Fatalf("Unexpected error: %v", err)
 		}
@@ -28,3 +30,102 @@ func TestResults(t *testing.T) {
 		}
 	})
 }
+
+// rescan removes rolls by scanning the whole plan for each wave.
+func rescan(plan FloorPlan, rule Rule) []int {
+	var res []int
+	for {
+		var removed [][2]int
+		for y, row := range plan {
+			for x, tile := range row {
+				if tile != Tile_PaperRoll {
+					continue
+				}
+				var neighbors int
+				for _, o := range rule.Neighborhood.offsets() {
+					x2, y2 := x+o.X, y+o.Y
+					if x2 >= 0 && x2 < len(row) && y2 >= 0 && y2 < len(plan) && plan[y2][x2] == Tile_PaperRoll {
+						neighbors++
+					}
+				}
+				if neighbors < rule.Threshold {
+					removed = append(removed, [2]int{x, y})
+					if rule.InPlace {
+						plan[y][x] = Tile_Clear
+					}
+				}
+			}
+		}
+		if len(removed) == 0 {
+			return res
+		}
+		for _, c := range removed {
+			plan[c[1]][c[0]] = Tile_Clear
+		}
+		res = append(res, len(removed))
+	}
+}
+
+func randomPlan(rng *rand.Rand) FloorPlan {
+	plan := make(FloorPlan, 1+rng.IntN(20))
+	width, density := 1+rng.IntN(20), rng.Float64()
+	for y := range plan {
+		plan[y] = make([]Tile, width)
+		for x := range plan[y] {
+			if rng.Float64() < density {
+				plan[y][x] = Tile_PaperRoll
+			}
+		}
+	}
+	return plan
+}
+
+func TestAutomaton(t *testing.T) {
+	rng := rand.New(rand.NewPCG(4, 4))
+	for range 500 {
+		plan := randomPlan(rng)
+		rule := Rule{Threshold: rng.IntN(6), Neighborhood: Neighborhood(rng.IntN(2)), InPlace: rng.IntN(2) == 0}
+		a, err := NewAutomaton(plan, rule)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		got := a.Run()
+		want := rescan(plan, rule)
+		if !slices.Equal(got, want) {
+			t.Errorf("%+v: got waves %v, want %v", rule, got, want)
+		}
+		if !slices.EqualFunc(a.Plan(), plan, slices.Equal) {
+			t.Errorf("%+v: got plan %v, want %v", rule, a.Plan(), plan)
+		}
+	}
+}
+
+func TestWaves(t *testing.T) {
+	plan, err := LoadFloorPlan(filepath.Join("testdata", "example.txt"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	a, err := NewAutomaton(plan, DefaultRule)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// The waves from the puzzle description.
+	if got, want := a.Run(), []int{13, 12, 7, 5, 2, 1, 1, 1, 1}; !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if _, err := NewAutomaton(plan, Rule{Threshold: -1}); err == nil {
+		t.Error("got no error for a negative threshold")
+	}
+}
+
+func TestParseNeighborhood(t *testing.T) {
+	for _, n := range []Neighborhood{Moore, VonNeumann} {
+		got, err := ParseNeighborhood(n.String())
+		if err != nil || got != n {
+			t.Errorf("got %v, %v, want %v", got, err, n)
+		}
+	}
+	if _, err := ParseNeighborhood("hexagonal"); err == nil {
+		t.Error("got no error for an unknown neighborhood")
+	}
+}
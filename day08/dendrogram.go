@@ -0,0 +1,181 @@
+package day08
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// Merge joins two clusters by the closest pair of points between them.
+type Merge struct {
+	Pair
+	// Left and Right are the joined clusters. Clusters 0 to n-1 are the single points, and the k-th merge forms
+	// cluster n+k.
+	Left, Right int
+	// Size is the number of points in the joined cluster.
+	Size int
+	// Rank is the position of the pair among all pairs by distance, starting at 0.
+	Rank int
+}
+
+// Dendrogram is the single-linkage clustering of points: Starting from single points, it keeps joining the two closest
+// clusters until all points are in one. The merges are the edges of a minimum spanning tree.
+type Dendrogram struct {
+	Points []*Point3D
+	Metric Metric
+	Merges []Merge
+}
+
+// NewDendrogram clusters the points with Kruskal's algorithm on the stream of pairs.
+func NewDendrogram(points []*Point3D, metric Metric) *Dendrogram {
+	d := &Dendrogram{Points: points, Metric: metric}
+	if len(points) < 2 {
+		return d
+	}
+	c := newCircuits(len(points))
+	// cluster maps the root of each circuit to its cluster.
+	cluster := make([]int, len(points))
+	for i := range cluster {
+		cluster[i] = i
+	}
+	var rank int
+	for pair := range Pairs(points, metric) {
+		ri, rj := c.find(pair.I), c.find(pair.J)
+		if c.union(ri, rj) {
+			r := c.find(ri)
+			d.Merges = append(d.Merges, Merge{
+				Pair:  pair,
+				Left:  cluster[ri],
+				Right: cluster[rj],
+				Size:  c.size[r],
+				Rank:  rank,
+			})
+			cluster[r] = len(points) + len(d.Merges) - 1
+			if c.count == 1 {
+				break
+			}
+		}
+		rank++
+	}
+	return d
+}
+
+// SpanningTree returns the edges of the minimum spanning tree in increasing distance.
+func (d *Dendrogram) SpanningTree() []Pair {
+	res := make([]Pair, len(d.Merges))
+	for i, m := range d.Merges {
+		res[i] = m.Pair
+	}
+	return res
+}
+
+// Connecting returns the merge that joined all points into one cluster. It returns false if there are fewer than two
+// points.
+func (d *Dendrogram) Connecting() (Merge, bool) {
+	if len(d.Merges) == 0 {
+		return Merge{}, false
+	}
+	return d.Merges[len(d.Merges)-1], true
+}
+
+// Clusters returns the clusters after the first k merges, each as its sorted point indexes. Larger clusters come first,
+// and equally large ones by their first index.
+func (d *Dendrogram) Clusters(k int) [][]int {
+	k = max(0, min(k, len(d.Merges)))
+	c := newCircuits(len(d.Points))
+	for _, m := range d.Merges[:k] {
+		c.union(m.I, m.J)
+	}
+	byRoot := make(map[int][]int)
+	for i := range d.Points {
+		r := c.find(i)
+		byRoot[r] = append(byRoot[r], i)
+	}
+	res := make([][]int, 0, len(byRoot))
+	for _, cluster := range byRoot {
+		res = append(res, cluster)
+	}
+	slices.SortFunc(res, func(a, b []int) int {
+		return cmp.Or(cmp.Compare(len(b), len(a)), cmp.Compare(a[0], b[0]))
+	})
+	return res
+}
+
+// root returns the cluster with all points, or -1 if there are none.
+func (d *Dendrogram) root() int {
+	return len(d.Points) + len(d.Merges) - 1
+}
+
+// Newick returns the dendrogram in the Newick format. The leaves are labelled with the points, and the branch lengths
+// are the differences of the merge distances.
+func (d *Dendrogram) Newick() string {
+	var sb strings.Builder
+	if r := d.root(); r >= 0 {
+		d.writeNewick(&sb, r, d.height(r))
+	}
+	sb.WriteString(";")
+	return sb.String()
+}
+
+// height returns the merge distance of a cluster, which is 0 for single points.
+func (d *Dendrogram) height(cluster int) float64 {
+	if cluster < len(d.Points) {
+		return 0
+	}
+	return d.Metric.Length(d.Merges[cluster-len(d.Points)].Dist)
+}
+
+func (d *Dendrogram) writeNewick(sb *strings.Builder, cluster int, parentHeight float64) {
+	h := d.height(cluster)
+	if cluster < len(d.Points) {
+		// Points contain commas, so their labels need quotes.
+		fmt.Fprintf(sb, "'%s'", d.Points[cluster])
+	} else {
+		m := d.Merges[cluster-len(d.Points)]
+		sb.WriteString("(")
+		d.writeNewick(sb, m.Left, h)
+		sb.WriteString(",")
+		d.writeNewick(sb, m.Right, h)
+		sb.WriteString(")")
+	}
+	if cluster != d.root() {
+		fmt.Fprintf(sb, ":%g", parentHeight-h)
+	}
+}
+
+// dendrogramNode is a cluster in the JSON export.
+type dendrogramNode struct {
+	ID       int               `json:"id"`
+	Point    string            `json:"point,omitempty"`
+	Dist     float64           `json:"distance,omitempty"`
+	Size     int               `json:"size"`
+	Children []*dendrogramNode `json:"children,omitempty"`
+}
+
+func (d *Dendrogram) node(cluster int) *dendrogramNode {
+	if cluster < len(d.Points) {
+		return &dendrogramNode{ID: cluster, Point: d.Points[cluster].String(), Size: 1}
+	}
+	m := d.Merges[cluster-len(d.Points)]
+	return &dendrogramNode{
+		ID:       cluster,
+		Dist:     d.Metric.Length(m.Dist),
+		Size:     m.Size,
+		Children: []*dendrogramNode{d.node(m.Left), d.node(m.Right)},
+	}
+}
+
+// WriteJSON writes the dendrogram as nested clusters. Single points have their coordinates, and joined clusters have
+// their merge distance and the two clusters they join.
+func (d *Dendrogram) WriteJSON(w io.Writer) error {
+	var root *dendrogramNode
+	if r := d.root(); r >= 0 {
+		root = d.node(r)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(root)
+}
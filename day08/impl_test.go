@@ -1,7 +1,12 @@
 package day08
 
 import (
+	"cmp"
+	"encoding/json"
+	"math/rand/v2"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -9,7 +14,7 @@ func TestResults(t *testing.T) {
 	testFilePath := filepath.Join("testdata", "example.txt")
 	t.Run("Round 1", func(t *testing.T) {
 		const want = 40
-		got, err := Round1(testFilePath, 10, 3, false)
+		got, err := Round1(testFilePath, 10, 3, Euclidean, false)
 		if err != nil {
 			t.Fatalf("Unexpected error: %v", err)
 		}
@@ -19,7 +24,7 @@ func TestResults(t *testing.T) {
 	})
 	t.Run("Round 2", func(t *testing.T) {
 		const want = 25272
-		got, err := Round2(testFilePath, false)
+		got, err := Round2(testFilePath, Euclidean, false)
 		if err != nil {
 			t.Fatalf("Unexpected error: %v", err)
 		}
@@ -28,3 +33,183 @@ func TestResults(t *testing.T) {
 		}
 	})
 }
+
+func randomPoints(rng *rand.Rand, n, limit int) []*Point3D {
+	points := make([]*Point3D, n)
+	for i := range points {
+		points[i] = &Point3D{X: rng.IntN(limit), Y: rng.IntN(limit), Z: rng.IntN(limit)}
+	}
+	return points
+}
+
+func TestPairs(t *testing.T) {
+	rng := rand.New(rand.NewPCG(8, 8))
+	// Small coordinates lead to many equally distant pairs.
+	for _, limit := range []int{5, 1000} {
+		points := randomPoints(rng, 200, limit)
+		for _, metric := range []Metric{Euclidean, Manhattan, Chebyshev} {
+			var want []Pair
+			for i := range points {
+				for j := i + 1; j < len(points); j++ {
+					want = append(want, Pair{I: i, J: j, Dist: metric.Dist(points[i], points[j])})
+				}
+			}
+			slices.SortFunc(want, func(a, b Pair) int {
+				return cmp.Or(cmp.Compare(a.Dist, b.Dist), cmp.Compare(a.I, b.I), cmp.Compare(a.J, b.J))
+			})
+			got := slices.Collect(Pairs(points, metric))
+			if !slices.Equal(got, want) {
+				t.Errorf("%s, limit %d: got %d pairs, want %d pairs in order", metric, limit, len(got), len(want))
+			}
+		}
+	}
+}
+
+func TestNearest(t *testing.T) {
+	rng := rand.New(rand.NewPCG(8, 8))
+	points := randomPoints(rng, 500, 50)
+	for _, metric := range []Metric{Euclidean, Manhattan, Chebyshev} {
+		tree := NewKDTree(points, metric)
+		for _, q := range randomPoints(rng, 20, 50) {
+			var want []Neighbour
+			for i, p := range points {
+				want = append(want, Neighbour{Index: i, Dist: metric.Dist(p, q)})
+			}
+			slices.SortFunc(want, func(a, b Neighbour) int {
+				return cmp.Or(cmp.Compare(a.Dist, b.Dist), cmp.Compare(a.Index, b.Index))
+			})
+			if got := tree.Nearest(q, 10, nil); !slices.Equal(got, want[:10]) {
+				t.Errorf("%s, %s: got %v, want %v", metric, q, got, want[:10])
+			}
+		}
+	}
+}
+
+// connectAll streams pairs until all points are connected and returns the number of pairs it took.
+func connectAll(points []*Point3D) (int, bool) {
+	c := newCircuits(len(points))
+	var n int
+	for pair := range Pairs(points, Euclidean) {
+		n++
+		if c.union(pair.I, pair.J) && c.count == 1 {
+			break
+		}
+	}
+	return n, c.count == 1
+}
+
+func TestScale(t *testing.T) {
+	rng := rand.New(rand.NewPCG(8, 8))
+	points := randomPoints(rng, 10_000, 100_000)
+	n, ok := connectAll(points)
+	if !ok {
+		t.Fatal("got more than one circuit, want 1")
+	}
+	t.Logf("connected %d points after %d pairs", len(points), n)
+}
+
+func BenchmarkScale(b *testing.B) {
+	rng := rand.New(rand.NewPCG(8, 8))
+	points := randomPoints(rng, 100_000, 100_000)
+	for b.Loop() {
+		if _, ok := connectAll(points); !ok {
+			b.Fatal("got more than one circuit, want 1")
+		}
+	}
+}
+
+func TestDendrogram(t *testing.T) {
+	points, err := LoadPoints(filepath.Join("testdata", "example.txt"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	d := NewDendrogram(points, Euclidean)
+	if got, want := len(d.Merges), len(points)-1; got != want {
+		t.Fatalf("got %d merges, want %d", got, want)
+	}
+	m, ok := d.Connecting()
+	if !ok {
+		t.Fatal("got no connecting merge")
+	}
+	if got, want := points[m.I].X*points[m.J].X, 25272; got != want {
+		t.Errorf("got %d for the connecting merge, want %d", got, want)
+	}
+	// Round 1 connects the closest 10 pairs, so it sees the merges among them.
+	var k int
+	for k < len(d.Merges) && d.Merges[k].Rank < 10 {
+		k++
+	}
+	clusters := d.Clusters(k)
+	if got, want := len(clusters[0])*len(clusters[1])*len(clusters[2]), 40; got != want {
+		t.Errorf("got %d for the clusters after %d merges, want %d", got, k, want)
+	}
+	if got := d.Clusters(0); len(got) != len(points) {
+		t.Errorf("got %d clusters before any merge, want %d", len(got), len(points))
+	}
+	if got := d.Clusters(len(d.Merges)); len(got) != 1 || len(got[0]) != len(points) {
+		t.Errorf("got %v after all merges, want a single cluster", got)
+	}
+	newick := d.Newick()
+	if got := strings.Count(newick, "'") / 2; got != len(points) || !strings.HasSuffix(newick, ";") {
+		t.Errorf("got %d leaves in %s, want %d", got, newick, len(points))
+	}
+	var sb strings.Builder
+	if err := d.WriteJSON(&sb); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var root dendrogramNode
+	if err := json.Unmarshal([]byte(sb.String()), &root); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if root.Size != len(points) || root.Dist != d.Metric.Length(m.Dist) {
+		t.Errorf("got root of size %d at %g, want %d at %g", root.Size, root.Dist, len(points), d.Metric.Length(m.Dist))
+	}
+}
+
+func TestSpanningTree(t *testing.T) {
+	rng := rand.New(rand.NewPCG(8, 8))
+	points := randomPoints(rng, 100, 10)
+	// Prim's algorithm on all pairs yields a tree of the same weight.
+	var want int
+	inTree := make([]bool, len(points))
+	dist := make([]int, len(points))
+	for i := range dist {
+		dist[i] = points[0].SqDist(points[i])
+	}
+	inTree[0] = true
+	for range len(points) - 1 {
+		next := -1
+		for i := range points {
+			if !inTree[i] && (next < 0 || dist[i] < dist[next]) {
+				next = i
+			}
+		}
+		want += dist[next]
+		inTree[next] = true
+		for i := range points {
+			dist[i] = min(dist[i], points[next].SqDist(points[i]))
+		}
+	}
+	var got int
+	for _, p := range NewDendrogram(points, Euclidean).SpanningTree() {
+		got += p.Dist
+	}
+	if got != want {
+		t.Errorf("got weight %d, want %d", got, want)
+	}
+}
+
+func TestParseMetric(t *testing.T) {
+	for _, metric := range []Metric{Euclidean, Manhattan, Chebyshev} {
+		got, err := ParseMetric(metric.String())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got != metric {
+			t.Errorf("got %s, want %s", got, metric)
+		}
+	}
+	if _, err := ParseMetric("hamming"); err == nil {
+		t.Error("got no error for an unknown metric")
+	}
+}
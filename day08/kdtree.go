@@ -0,0 +1,121 @@
+package day08
+
+import (
+	"cmp"
+	"container/heap"
+	"slices"
+)
+
+// KDTree indexes points for nearest neighbour queries. Each node splits the space along one axis, cycling through X,
+// Y and Z with the depth.
+type KDTree struct {
+	points []*Point3D
+	metric Metric
+	// order holds the indexes of the points. The subtree over order[lo:hi] has its root at the middle, with the
+	// smaller half on the left and the larger half on the right.
+	order []int
+}
+
+// Neighbour is a point found by a query, given by its index, along with its distance under the metric of the tree.
+type Neighbour struct {
+	Index int
+	Dist  int
+}
+
+func NewKDTree(points []*Point3D, metric Metric) *KDTree {
+	t := &KDTree{points: points, metric: metric, order: make([]int, len(points))}
+	for i := range t.order {
+		t.order[i] = i
+	}
+	t.build(0, len(points), 0)
+	return t
+}
+
+func coord(p *Point3D, axis int) int {
+	switch axis {
+	case 0:
+		return p.X
+	case 1:
+		return p.Y
+	}
+	return p.Z
+}
+
+func (t *KDTree) build(lo, hi, depth int) {
+	if hi-lo <= 1 {
+		return
+	}
+	axis := depth % 3
+	slices.SortFunc(t.order[lo:hi], func(a, b int) int {
+		return cmp.Compare(coord(t.points[a], axis), coord(t.points[b], axis))
+	})
+	mid := (lo + hi) / 2
+	t.build(lo, mid, depth+1)
+	t.build(mid+1, hi, depth+1)
+}
+
+// Nearest returns the k points closest to q among those that pass the filter, closest first. Equally distant points
+// are ordered by their index. A nil filter accepts all points.
+func (t *KDTree) Nearest(q *Point3D, k int, filter func(i int) bool) []Neighbour {
+	if k <= 0 {
+		return nil
+	}
+	var best neighbourHeap
+	t.nearest(q, k, filter, 0, len(t.order), 0, &best)
+	res := make([]Neighbour, len(best))
+	for i := len(res) - 1; i >= 0; i-- {
+		res[i] = heap.Pop(&best).(Neighbour)
+	}
+	return res
+}
+
+func (t *KDTree) nearest(q *Point3D, k int, filter func(i int) bool, lo, hi, depth int, best *neighbourHeap) {
+	if lo >= hi {
+		return
+	}
+	mid := (lo + hi) / 2
+	i := t.order[mid]
+	p := t.points[i]
+	if filter == nil || filter(i) {
+		n := Neighbour{Index: i, Dist: t.metric.Dist(p, q)}
+		if len(*best) < k {
+			heap.Push(best, n)
+		} else if n.less((*best)[0]) {
+			(*best)[0] = n
+			heap.Fix(best, 0)
+		}
+	}
+	axis := depth % 3
+	d := coord(q, axis) - coord(p, axis)
+	near, far := [2]int{lo, mid}, [2]int{mid + 1, hi}
+	if d > 0 {
+		near, far = far, near
+	}
+	t.nearest(q, k, filter, near[0], near[1], depth+1, best)
+	// The far side can only hold closer points if the splitting plane is close enough. Equal distances still count,
+	// since the index might break the tie.
+	if len(*best) < k || t.metric.bound(d) <= (*best)[0].Dist {
+		t.nearest(q, k, filter, far[0], far[1], depth+1, best)
+	}
+}
+
+func (n Neighbour) less(o Neighbour) bool {
+	if n.Dist != o.Dist {
+		return n.Dist < o.Dist
+	}
+	return n.Index < o.Index
+}
+
+// neighbourHeap is a max-heap, so that the worst of the best neighbours so far is at the top.
+type neighbourHeap []Neighbour
+
+func (h neighbourHeap) Len() int           { return len(h) }
+func (h neighbourHeap) Less(i, j int) bool { return h[j].less(h[i]) }
+func (h neighbourHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *neighbourHeap) Push(x any)        { *h = append(*h, x.(Neighbour)) }
+func (h *neighbourHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
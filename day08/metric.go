@@ -0,0 +1,73 @@
+package day08
+
+import (
+	"fmt"
+	"math"
+)
+
+// Metric measures the distance between junction boxes. Distances are integers, so that equally distant pairs are
+// recognized as such.
+type Metric int
+
+const (
+	// Euclidean is the straight-line distance. Its integer distances are squared.
+	Euclidean Metric = iota
+	// Manhattan is the sum of the distances along each axis.
+	Manhattan
+	// Chebyshev is the largest distance along any axis.
+	Chebyshev
+)
+
+var metricNames = [...]string{
+	Euclidean: "euclidean",
+	Manhattan: "manhattan",
+	Chebyshev: "chebyshev",
+}
+
+func ParseMetric(name string) (Metric, error) {
+	for m, n := range metricNames {
+		if n == name {
+			return Metric(m), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown metric %q", name)
+}
+
+func (m Metric) String() string {
+	return metricNames[m]
+}
+
+// Dist returns the integer distance between the points.
+func (m Metric) Dist(p, q *Point3D) int {
+	switch m {
+	case Manhattan:
+		return abs(p.X-q.X) + abs(p.Y-q.Y) + abs(p.Z-q.Z)
+	case Chebyshev:
+		return max(abs(p.X-q.X), abs(p.Y-q.Y), abs(p.Z-q.Z))
+	}
+	return p.SqDist(q)
+}
+
+// bound returns the integer distance of two points that differ by d along a single axis. Under all metrics, that is
+// a lower bound for points that differ by d along one axis and arbitrarily along the others.
+func (m Metric) bound(d int) int {
+	if m == Euclidean {
+		return d * d
+	}
+	return abs(d)
+}
+
+// Length turns an integer distance into the actual distance.
+func (m Metric) Length(dist int) float64 {
+	if m == Euclidean {
+		return math.Sqrt(float64(dist))
+	}
+	return float64(dist)
+}
+
+func abs(i int) int {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
@@ -0,0 +1,168 @@
+package day08
+
+import (
+	"container/heap"
+	"iter"
+)
+
+// initialNeighbours is the number of neighbours to look up for each point at first. Most points never need more.
+const initialNeighbours = 4
+
+// Pair is two points, given by their indexes I < J, along with their distance under a metric.
+type Pair struct {
+	I, J int
+	Dist int
+}
+
+func (p Pair) less(o Pair) bool {
+	if p.Dist != o.Dist {
+		return p.Dist < o.Dist
+	}
+	if p.I != o.I {
+		return p.I < o.I
+	}
+	return p.J < o.J
+}
+
+// Pairs streams all pairs of points in increasing distance under the metric, equally distant pairs ordered by their
+// indexes. Rather than computing all pairs up front, it merges the neighbours of each point, which it looks up as
+// needed. Both points of a pair find each other with the same order key, so the two copies come out right after one
+// another, and the second is dropped.
+func Pairs(points []*Point3D, metric Metric) iter.Seq[Pair] {
+	return func(yield func(Pair) bool) {
+		tree := NewKDTree(points, metric)
+		streams := make([]*neighbourStream, len(points))
+		var h pairHeap
+		for i := range points {
+			streams[i] = &neighbourStream{tree: tree, i: i, k: initialNeighbours}
+			if n, ok := streams[i].next(); ok {
+				h = append(h, streams[i].pair(n))
+			}
+		}
+		heap.Init(&h)
+		var last Pair
+		for len(h) > 0 {
+			p := h[0].Pair
+			if p != last {
+				if !yield(p) {
+					return
+				}
+				last = p
+			}
+			s := streams[h[0].stream]
+			if n, ok := s.next(); ok {
+				h[0] = s.pair(n)
+				heap.Fix(&h, 0)
+			} else {
+				heap.Pop(&h)
+			}
+		}
+	}
+}
+
+// neighbourStream returns the neighbours of a point, closest first. It looks them up in batches of doubling size.
+// Leaving out the neighbours with smaller indexes would halve the pairs, but a filtered query has to search most of
+// the tree for the points with large indexes.
+type neighbourStream struct {
+	tree  *KDTree
+	i     int
+	k     int
+	batch []Neighbour
+	pos   int
+	// done is set once the batch holds all remaining neighbours.
+	done bool
+}
+
+func (s *neighbourStream) next() (Neighbour, bool) {
+	if s.pos == len(s.batch) {
+		if s.done {
+			return Neighbour{}, false
+		}
+		if s.batch != nil {
+			s.k *= 2
+		}
+		s.batch = s.tree.Nearest(s.tree.points[s.i], s.k, func(j int) bool { return j != s.i })
+		s.done = len(s.batch) < s.k
+		if s.pos == len(s.batch) {
+			return Neighbour{}, false
+		}
+	}
+	n := s.batch[s.pos]
+	s.pos++
+	return n, true
+}
+
+// pair returns the pair of the point with its neighbour. Since the neighbours come in order of their index when
+// equally distant, so do the pairs.
+func (s *neighbourStream) pair(n Neighbour) streamPair {
+	return streamPair{Pair: Pair{I: min(s.i, n.Index), J: max(s.i, n.Index), Dist: n.Dist}, stream: s.i}
+}
+
+// streamPair is a pair along with the point whose stream found it.
+type streamPair struct {
+	Pair
+	stream int
+}
+
+type pairHeap []streamPair
+
+func (h pairHeap) Len() int           { return len(h) }
+func (h pairHeap) Less(i, j int) bool { return h[i].less(h[j].Pair) }
+func (h pairHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *pairHeap) Push(x any)        { *h = append(*h, x.(streamPair)) }
+func (h *pairHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// circuits keeps track of the connected junction boxes as a disjoint-set forest with union by size.
+type circuits struct {
+	parent, size []int
+	// count is the number of distinct circuits.
+	count int
+}
+
+func newCircuits(n int) *circuits {
+	c := &circuits{parent: make([]int, n), size: make([]int, n), count: n}
+	for i := range c.parent {
+		c.parent[i] = i
+		c.size[i] = 1
+	}
+	return c
+}
+
+func (c *circuits) find(i int) int {
+	for c.parent[i] != i {
+		c.parent[i] = c.parent[c.parent[i]]
+		i = c.parent[i]
+	}
+	return i
+}
+
+// union connects the circuits of i and j. It returns false if they are connected already.
+func (c *circuits) union(i, j int) bool {
+	ri, rj := c.find(i), c.find(j)
+	if ri == rj {
+		return false
+	}
+	if c.size[ri] < c.size[rj] {
+		ri, rj = rj, ri
+	}
+	c.parent[rj] = ri
+	c.size[ri] += c.size[rj]
+	c.count--
+	return true
+}
+
+// sizes returns the sizes of all circuits.
+func (c *circuits) sizes() []int {
+	var res []int
+	for i, p := range c.parent {
+		if i == p {
+			res = append(res, c.size[i])
+		}
+	}
+	return res
+}
@@ -2,12 +2,14 @@ package day09
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"os"
 	"strconv"
 	"strings"
 
 	"github.com/sekruse/adventofcode2025/day07"
+	"github.com/sekruse/adventofcode2025/polygon"
 )
 
 type Point2D = day07.Point2D
@@ -17,53 +19,79 @@ func Round1(path string, verbose bool) (int, error) {
 	if err != nil {
 		return 0, err
 	}
-	// Compute pairwise distances of points.
-	var maxSquareSize int
-	for i := 0; i < len(points)-1; i++ {
-		p := points[i]
-		for j := i + 1; j < len(points); j++ {
-			q := points[j]
-			size := (Abs(p.X-q.X) + 1) * (Abs(p.Y-q.Y) + 1)
-			if size > maxSquareSize {
-				maxSquareSize = size
-			}
-		}
-	}
-	return maxSquareSize, nil
+	return LargestRectangle(points, nil, verbose).Size, nil
 }
 
-func Round2(path string, verbose bool) (int, error) {
-	points, err := LoadPoints(path)
+// Round2 expects the red tiles to form a closed rectilinear polygon that does not cross itself; touching is only
+// reported as a warning on stderr. If lenient, so are the other violations.
+func Round2(path string, lenient, verbose bool) (int, error) {
+	points, poly, err := LoadPolygon(path, lenient, os.Stderr)
 	if err != nil {
 		return 0, err
 	}
-	// Figure out the orientation of the cycle.
-	orientation, err := Orientation(points, verbose)
-	if err != nil {
-		return 0, err
-	}
-	// Create vectors that point to where "outside" is around the perimeter.
-	pv := PerimeterVectors(points, orientation, verbose)
+	return LargestInnerRectangle(points, poly, verbose).Size, nil
+}
+
+// Rectangle is spanned by two red tiles as opposite corners.
+type Rectangle struct {
+	P, Q *Point2D
+	// Size is the number of tiles in the rectangle.
+	Size int
+}
+
+// LargestRectangle finds the largest rectangle among those that fit, or among all if fits is nil. The result has no
+// corners if no rectangle fits.
+func LargestRectangle(points []*Point2D, fits func(p, q *Point2D) bool, verbose bool) *Rectangle {
 	// Compute pairwise distances of points.
-	var maxSquareSize int
+	var res Rectangle
 	for i := 0; i < len(points)-1; i++ {
 		p := points[i]
 		for j := i + 1; j < len(points); j++ {
 			q := points[j]
-			if verbose {
-				fmt.Printf("Testing %s and %s.\n", p, q)
-			}
 			size := (Abs(p.X-q.X) + 1) * (Abs(p.Y-q.Y) + 1)
-			if size <= maxSquareSize {
+			if size <= res.Size {
 				continue
 			}
-			if !IsBoxInPerimeter(p, q, pv, verbose) {
-				continue
+			if fits != nil {
+				if verbose {
+					fmt.Printf("Testing %s and %s.\n", p, q)
+				}
+				if !fits(p, q) {
+					continue
+				}
 			}
-			maxSquareSize = size
+			res = Rectangle{P: p, Q: q, Size: size}
 		}
 	}
-	return maxSquareSize, nil
+	return &res
+}
+
+// LargestInnerRectangle finds the largest rectangle that lies inside the polygon formed by the points.
+func LargestInnerRectangle(points []*Point2D, poly *polygon.Polygon, verbose bool) *Rectangle {
+	if verbose {
+		fmt.Printf("Polygon with %d corners, area %d and orientation %+d.\n", len(points), poly.Area(), poly.Orientation())
+		for _, c := range poly.Contacts() {
+			fmt.Printf("The perimeter %s.\n", c)
+		}
+	}
+	// On the compressed grid, each rectangle check is a lookup in the summed-area table.
+	raster := poly.Rasterize()
+	if verbose {
+		rows, cols := raster.Size()
+		fmt.Printf("Compressed grid with %d rows and %d columns.\n", rows, cols)
+	}
+	return LargestRectangle(points, func(p, q *Point2D) bool {
+		return raster.ContainsRect(polygon.Point(*p), polygon.Point(*q))
+	}, verbose)
+}
+
+// NewPolygon creates a polygon with the points as its corners. The perimeter may touch itself.
+func NewPolygon(points []*Point2D) (*polygon.Polygon, error) {
+	vertices := make([]polygon.Point, len(points))
+	for i, p := range points {
+		vertices[i] = polygon.Point(*p)
+	}
+	return polygon.New(vertices)
 }
 
 func ParsePoint2D(enc string) (*Point2D, error) {
@@ -89,146 +117,27 @@ func LoadPoints(path string) ([]*Point2D, error) {
 	if err != nil {
 		return nil, err
 	}
+	defer file.Close()
 	var points []*Point2D
+	// Collect the errors of all lines, so that they can be fixed at once.
+	var errs []error
 	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
+	for lineNo := 1; scanner.Scan(); lineNo++ {
 		line := scanner.Text()
 		p, err := ParsePoint2D(line)
 		if err != nil {
-			return nil, err
+			errs = append(errs, fmt.Errorf("line %d: %w", lineNo, err))
+			continue
 		}
 		points = append(points, p)
 	}
 	if err := scanner.Err(); err != nil {
 		return nil, err
 	}
-	return points, nil
-}
-
-// Orientation determines if the cycle is oriented positively or negatively.
-func Orientation(cycle []*Point2D, verbose bool) (int, error) {
-	if len(cycle) < 4 {
-		return 0, fmt.Errorf("expecting at least 4 points to form a box, got %d points", len(cycle))
-	}
-	dWrap := Point2D{
-		X: Sign(cycle[0].X - cycle[len(cycle)-1].X),
-		Y: Sign(cycle[0].Y - cycle[len(cycle)-1].Y),
-	}
-	dPrev := dWrap
-	pPrev := cycle[0]
-	var orientation int
-	for i := 1; i < len(cycle); i++ {
-		p := cycle[i]
-		d := Point2D{
-			X: Sign(p.X - pPrev.X),
-			Y: Sign(p.Y - pPrev.Y),
-		}
-		det := Determinant(&dPrev, &d)
-		orientation += det
-		if verbose {
-			fmt.Printf("%s -> %s: %+d %+d\n", &dPrev, &d, det, orientation)
-		}
-		pPrev = p
-		dPrev = d
-	}
-	det := dPrev.X*dWrap.Y - dWrap.X*dPrev.Y
-	orientation += det
-	if verbose {
-		fmt.Printf("%s -> %s: %+d %+d\n", &dPrev, &dWrap, det, orientation)
-	}
-	return Sign(orientation), nil
-}
-
-func PerimeterVectors(cycle []*Point2D, orientation int, verbose bool) map[Point2D][]*Point2D {
-	res := make(map[Point2D][]*Point2D)
-	pPrev := cycle[0]
-	for i := 1; i < len(cycle)+1; i++ {
-		p := cycle[i%len(cycle)]
-		d := Point2D{
-			X: Sign(p.X - pPrev.X),
-			Y: Sign(p.Y - pPrev.Y),
-		}
-		// Turn against the cycles orientation to point outwards.
-		dRot := Point2D{
-			X: d.Y * orientation,
-			Y: -d.X * orientation,
-		}
-		q := pPrev
-		for {
-			res[*q] = append(res[*q], &dRot)
-			if verbose {
-				fmt.Printf("%s -> %s\n", q, &dRot)
-			}
-			if *q == *p {
-				break
-			}
-			q = &Point2D{
-				X: q.X + d.X,
-				Y: q.Y + d.Y,
-			}
-		}
-		pPrev = p
-	}
-	// We need to remove the perimeter vectors for "concave" corners.
-	pPrev = cycle[len(cycle)-1]
-	pPrevPrev := cycle[len(cycle)-2]
-	dPrev := Point2D{
-		X: Sign(pPrev.X - pPrevPrev.X),
-		Y: Sign(pPrev.Y - pPrevPrev.Y),
-	}
-	for i := 0; i < len(cycle); i++ {
-		p := cycle[i]
-		d := Point2D{
-			X: Sign(p.X - pPrev.X),
-			Y: Sign(p.Y - pPrev.Y),
-		}
-		do := Determinant(&dPrev, &d)
-		if do != orientation {
-			if verbose {
-				fmt.Printf("Detected concave corner at %s.\n", pPrev)
-			}
-			delete(res, *pPrev)
-		}
-		pPrev = p
-		dPrev = d
-	}
-	return res
-}
-
-func IsBoxInPerimeter(p, q *Point2D, pv map[Point2D][]*Point2D, verbose bool) bool {
-	corners := []*Point2D{
-		p,
-		{X: p.X, Y: q.Y},
-		q,
-		{X: q.X, Y: p.Y},
-	}
-	src := corners[3]
-	for i := 0; i < len(corners); i++ {
-		// March along each edge and see if we're leaving the perimiter.
-		dst := corners[i]
-		d := Point2D{
-			X: Sign(dst.X - src.X),
-			Y: Sign(dst.Y - src.Y),
-		}
-		for r := src; *r != *dst; {
-			// Check if we're at the perimeter and moving outside.
-			vs, ok := pv[*r]
-			if ok {
-				for _, v := range vs {
-					if *v == d {
-						if verbose {
-							fmt.Printf("Stepping out of bounds at %s in the direction %s.\n", r, &d)
-						}
-						// TODO: The perimeter might "touch" itself, so we're crossing the perimeter in and out simultaneously.
-						return false
-					}
-				}
-			}
-			r = &Point2D{X: r.X + d.X, Y: r.Y + d.Y}
-		}
-		src = dst
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
 	}
-	return true
+	return points, nil
 }
 
 func Abs(i int) int {
@@ -237,17 +146,3 @@ func Abs(i int) int {
 	}
 	return i
 }
-
-func Sign(i int) int {
-	if i < 0 {
-		return -1
-	}
-	if i > 0 {
-		return 1
-	}
-	return 0
-}
-
-func Determinant(a, b *Point2D) int {
-	return a.X*b.Y - b.X*a.Y
-}
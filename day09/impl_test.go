@@ -1,7 +1,11 @@
 package day09
 
 import (
+	"image/png"
+	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -19,7 +23,7 @@ func TestResults(t *testing.T) {
 	})
 	t.Run("Round 2", func(t *testing.T) {
 		const want = 24
-		got, err := Round2(testFilePath, false)
+		got, err := Round2(testFilePath, false, false)
 		if err != nil {
 			t.Fatalf("Unexpected error: %v", err)
 		}
@@ -28,3 +32,119 @@ func TestResults(t *testing.T) {
 		}
 	})
 }
+
+func TestTouchingPerimeter(t *testing.T) {
+	// The lower part of the polygon reaches up and runs along the upper part, so the perimeter touches itself. The
+	// best rectangle goes across the touching edges.
+	testFilePath := filepath.Join("testdata", "touching.txt")
+	const want = 32
+	got, err := Round2(testFilePath, false, false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got != want {
+		t.Errorf("got %d, want %d", got, want)
+	}
+	// That is not a simple polygon, which is only worth a warning.
+	var warnings strings.Builder
+	if _, _, err := LoadPolygon(testFilePath, false, &warnings); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(warnings.String(), "warning:") || !strings.Contains(warnings.String(), "touches itself") {
+		t.Errorf("got warnings %q, want the perimeter touching itself", warnings.String())
+	}
+}
+
+func TestRender(t *testing.T) {
+	dir := t.TempDir()
+	opts := RenderOptions{
+		SVGPath: filepath.Join(dir, "example.svg"),
+		PNGPath: filepath.Join(dir, "example.png"),
+		Size:    200,
+		Labels:  true,
+	}
+	if err := Render(filepath.Join("testdata", "example.txt"), opts, false); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	svg, err := os.ReadFile(opts.SVGPath)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, want := range []string{"<svg", "Round 1: 50 tiles", "Round 2: 24 tiles", ">7,1</text>", "</svg>"} {
+		if !strings.Contains(string(svg), want) {
+			t.Errorf("SVG does not contain %q", want)
+		}
+	}
+	file, err := os.Open(opts.PNGPath)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer file.Close()
+	img, err := png.Decode(file)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := img.Bounds().Dx(); got != opts.Size {
+		t.Errorf("got width %d, want %d", got, opts.Size)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		points string
+		want   []string
+	}{
+		{"Valid", "0,0 4,0 4,4 0,4", nil},
+		{"Too few", "0,0 4,0 4,4", []string{"expecting at least 4 red tiles, got 3"}},
+		{"Not closed", "0,0 4,0 4,4 1,4", []string{
+			"lines 4, 1: red tiles (1, 4) and (0, 0) are neither in the same row nor in the same column, so the loop does not close",
+		}},
+		{"Diagonal", "0,0 4,0 2,2 0,2", []string{
+			"lines 2, 3: red tiles (4, 0) and (2, 2) are neither in the same row nor in the same column",
+		}},
+		{"Repeated", "0,0 2,0 2,2 4,2 4,4 2,4 2,2 0,2", []string{
+			"lines 3, 7: red tile (2, 2) repeats",
+		}},
+		{"Crossing", "0,2 4,2 4,4 2,4 2,0 0,0", []string{
+			"lines 1, 2, 4, 5: the perimeter crosses itself at (2, 2)",
+		}},
+		{"Touching", "0,0 6,0 6,2 2,2 2,4 4,4 4,2 5,2 5,4 6,4 6,6 0,6", []string{
+			"lines 3, 4, 6, 7: the perimeter touches itself at (4, 2)",
+			"lines 3, 4, 7, 8: the perimeter touches itself at (4, 2)",
+			"lines 3, 4, 8, 9: the perimeter touches itself at (5, 2)",
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var points []*Point2D
+			for _, enc := range strings.Fields(tc.points) {
+				p, err := ParsePoint2D(enc)
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				points = append(points, p)
+			}
+			var got []string
+			for _, v := range Validate(points) {
+				got = append(got, v.String())
+			}
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+	// Parse errors report every line.
+	path := filepath.Join(t.TempDir(), "broken.txt")
+	if err := os.WriteFile(path, []byte("1,2\n3;4\n5,6\n7\n"), 0o644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	_, err := LoadPoints(path)
+	if err == nil {
+		t.Fatalf("got no error")
+	}
+	for _, want := range []string{"line 2:", "line 4:"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package day09
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"os"
+
+	"github.com/sekruse/adventofcode2025/polygon"
+)
+
+const (
+	// renderMargin keeps the perimeter vectors within the image.
+	renderMargin = 24
+	arrowLength  = 10
+)
+
+var (
+	background    = color.NRGBA{0xff, 0xff, 0xff, 0xff}
+	edgeColour    = color.NRGBA{0x40, 0x40, 0x40, 0xff}
+	vectorColour  = color.NRGBA{0x94, 0x67, 0xbd, 0xff}
+	tileColour    = color.NRGBA{0xd6, 0x27, 0x28, 0xff}
+	concaveColour = color.NRGBA{0xff, 0x7f, 0x0e, 0xff}
+	rectColours   = []color.NRGBA{{0x1f, 0x77, 0xb4, 0xff}, {0x2c, 0xa0, 0x2c, 0xff}}
+)
+
+// RenderOptions configures Render.
+type RenderOptions struct {
+	// SVGPath and PNGPath are the files to write to. Empty means that the format is skipped.
+	SVGPath, PNGPath string
+	// Size is the width and height of the images in pixels.
+	Size int
+	// Labels puts the coordinates next to each red tile. Only the SVG has labels, since the standard library cannot
+	// draw text.
+	Labels bool
+	// Lenient only warns about violations of the input, see Round2.
+	Lenient bool
+}
+
+// scene is the polygon along with the best rectangles of both rounds, scaled to fit the image.
+type scene struct {
+	points  []*Point2D
+	poly    *polygon.Polygon
+	concave map[int]bool
+	rects   []*Rectangle
+	size    int
+	labels  bool
+	minX    int
+	minY    int
+	scale   float64
+}
+
+// Render draws the red tiles with the perimeter vectors pointing outside, the concave corners and the largest rectangle
+// of each round.
+func Render(path string, opts RenderOptions, verbose bool) error {
+	points, poly, err := LoadPolygon(path, opts.Lenient, os.Stderr)
+	if err != nil {
+		return err
+	}
+	r1 := LargestRectangle(points, nil, false)
+	r2 := LargestInnerRectangle(points, poly, false)
+	s := newScene(points, poly, []*Rectangle{r1, r2}, opts)
+	if verbose {
+		fmt.Printf("%d red tiles, %d concave corners, scaled by %g.\n", len(points), len(s.concave), s.scale)
+		for i, r := range s.rects {
+			fmt.Printf("Round %d: %s to %s with %d tiles.\n", i+1, r.P, r.Q, r.Size)
+		}
+	}
+	if opts.SVGPath != "" {
+		if err := writeFile(opts.SVGPath, s.writeSVG); err != nil {
+			return err
+		}
+	}
+	if opts.PNGPath != "" {
+		if err := writeFile(opts.PNGPath, s.writePNG); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeFile(path string, write func(w io.Writer) error) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	w := bufio.NewWriter(file)
+	if err := write(w); err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+func newScene(points []*Point2D, poly *polygon.Polygon, rects []*Rectangle, opts RenderOptions) *scene {
+	s := &scene{
+		points:  points,
+		poly:    poly,
+		concave: make(map[int]bool),
+		size:    opts.Size,
+		labels:  opts.Labels,
+		minX:    points[0].X,
+		minY:    points[0].Y,
+	}
+	for _, r := range rects {
+		if r.P != nil {
+			s.rects = append(s.rects, r)
+		}
+	}
+	for _, i := range poly.ConcaveCorners() {
+		s.concave[i] = true
+	}
+	maxX, maxY := s.minX, s.minY
+	for _, p := range points {
+		s.minX, s.minY = min(s.minX, p.X), min(s.minY, p.Y)
+		maxX, maxY = max(maxX, p.X), max(maxY, p.Y)
+	}
+	s.scale = float64(s.size-2*renderMargin) / float64(max(maxX-s.minX, maxY-s.minY, 1))
+	return s
+}
+
+// project maps tile coordinates to pixels.
+func (s *scene) project(x, y int) (float64, float64) {
+	return renderMargin + float64(x-s.minX)*s.scale, renderMargin + float64(y-s.minY)*s.scale
+}
+
+func svgColour(c color.NRGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+func (s *scene) writeSVG(w io.Writer) error {
+	fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n", s.size, s.size, s.size, s.size)
+	fmt.Fprintf(w, `<rect width="100%%" height="100%%" fill="%s"/>`+"\n", svgColour(background))
+	for i, r := range s.rects {
+		x0, y0 := s.project(min(r.P.X, r.Q.X), min(r.P.Y, r.Q.Y))
+		x1, y1 := s.project(max(r.P.X, r.Q.X), max(r.P.Y, r.Q.Y))
+		c := svgColour(rectColours[i%len(rectColours)])
+		fmt.Fprintf(w, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s" fill-opacity="0.25" stroke="%s"><title>Round %d: %d tiles</title></rect>`+"\n",
+			x0, y0, x1-x0, y1-y0, c, c, i+1, r.Size)
+	}
+	fmt.Fprintf(w, `<polygon fill="none" stroke="%s" points="`, svgColour(edgeColour))
+	for _, p := range s.points {
+		x, y := s.project(p.X, p.Y)
+		fmt.Fprintf(w, "%.1f,%.1f ", x, y)
+	}
+	fmt.Fprintln(w, `"/>`)
+	for _, v := range s.vectors() {
+		fmt.Fprintf(w, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="%s"/>`+"\n", v[0], v[1], v[2], v[3], svgColour(vectorColour))
+		fmt.Fprintf(w, `<circle cx="%.1f" cy="%.1f" r="2" fill="%s"/>`+"\n", v[2], v[3], svgColour(vectorColour))
+	}
+	for i, p := range s.points {
+		x, y := s.project(p.X, p.Y)
+		if s.concave[i] {
+			fmt.Fprintf(w, `<circle cx="%.1f" cy="%.1f" r="5" fill="none" stroke="%s"/>`+"\n", x, y, svgColour(concaveColour))
+		}
+		fmt.Fprintf(w, `<circle cx="%.1f" cy="%.1f" r="2" fill="%s"/>`+"\n", x, y, svgColour(tileColour))
+		if s.labels {
+			fmt.Fprintf(w, `<text x="%.1f" y="%.1f" font-size="8" font-family="monospace">%d,%d</text>`+"\n", x+4, y-4, p.X, p.Y)
+		}
+	}
+	_, err := fmt.Fprintln(w, "</svg>")
+	return err
+}
+
+// vectors returns a line from the middle of each edge that points outside.
+func (s *scene) vectors() [][4]float64 {
+	var res [][4]float64
+	orientation := s.poly.Orientation()
+	for _, e := range s.poly.Edges() {
+		x0, y0 := s.project(e.From.X, e.From.Y)
+		x1, y1 := s.project(e.To.X, e.To.Y)
+		x, y := (x0+x1)/2, (y0+y1)/2
+		d := e.Outward(orientation)
+		res = append(res, [4]float64{x, y, x + float64(d.X*arrowLength), y + float64(d.Y*arrowLength)})
+	}
+	return res
+}
+
+func (s *scene) writePNG(w io.Writer) error {
+	img := image.NewNRGBA(image.Rect(0, 0, s.size, s.size))
+	draw.Draw(img, img.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+	for i, r := range s.rects {
+		x0, y0 := s.project(min(r.P.X, r.Q.X), min(r.P.Y, r.Q.Y))
+		x1, y1 := s.project(max(r.P.X, r.Q.X), max(r.P.Y, r.Q.Y))
+		c := rectColours[i%len(rectColours)]
+		fill := c
+		fill.A = 0x40
+		draw.Draw(img, image.Rect(int(x0), int(y0), int(x1)+1, int(y1)+1), image.NewUniform(fill), image.Point{}, draw.Over)
+		drawLine(img, x0, y0, x1, y0, c)
+		drawLine(img, x1, y0, x1, y1, c)
+		drawLine(img, x1, y1, x0, y1, c)
+		drawLine(img, x0, y1, x0, y0, c)
+	}
+	for _, e := range s.poly.Edges() {
+		x0, y0 := s.project(e.From.X, e.From.Y)
+		x1, y1 := s.project(e.To.X, e.To.Y)
+		drawLine(img, x0, y0, x1, y1, edgeColour)
+	}
+	for _, v := range s.vectors() {
+		drawLine(img, v[0], v[1], v[2], v[3], vectorColour)
+		drawDisc(img, v[2], v[3], 2, vectorColour)
+	}
+	for i, p := range s.points {
+		x, y := s.project(p.X, p.Y)
+		if s.concave[i] {
+			drawRing(img, x, y, 5, concaveColour)
+		}
+		drawDisc(img, x, y, 2, tileColour)
+	}
+	return png.Encode(w, img)
+}
+
+// drawLine draws a line one pixel wide by stepping along its longer axis.
+func drawLine(img *image.NRGBA, x0, y0, x1, y1 float64, c color.NRGBA) {
+	steps := int(max(Abs(int(x1-x0)), Abs(int(y1-y0)), 1))
+	for i := 0; i <= steps; i++ {
+		t := float64(i) / float64(steps)
+		img.SetNRGBA(int(x0+t*(x1-x0)+0.5), int(y0+t*(y1-y0)+0.5), c)
+	}
+}
+
+func drawDisc(img *image.NRGBA, x, y float64, r int, c color.NRGBA) {
+	cx, cy := int(x+0.5), int(y+0.5)
+	for dy := -r; dy <= r; dy++ {
+		for dx := -r; dx <= r; dx++ {
+			if dx*dx+dy*dy <= r*r {
+				img.SetNRGBA(cx+dx, cy+dy, c)
+			}
+		}
+	}
+}
+
+func drawRing(img *image.NRGBA, x, y float64, r int, c color.NRGBA) {
+	cx, cy := int(x+0.5), int(y+0.5)
+	for dy := -r; dy <= r; dy++ {
+		for dx := -r; dx <= r; dx++ {
+			if d := dx*dx + dy*dy; d <= r*r && d > (r-1)*(r-1) {
+				img.SetNRGBA(cx+dx, cy+dy, c)
+			}
+		}
+	}
+}
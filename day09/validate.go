@@ -0,0 +1,138 @@
+package day09
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/sekruse/adventofcode2025/polygon"
+)
+
+// Violation is a reason why the red tiles do not form a closed rectilinear simple polygon.
+type Violation struct {
+	// Lines are the line numbers of the red tiles involved, starting at 1.
+	Lines []int
+	Msg   string
+	// Warning marks violations that the polygon copes with, i.e., a perimeter that touches itself. All others lead to
+	// wrong answers.
+	Warning bool
+}
+
+func (v Violation) String() string {
+	switch len(v.Lines) {
+	case 0:
+		return v.Msg
+	case 1:
+		return fmt.Sprintf("line %d: %s", v.Lines[0], v.Msg)
+	}
+	lines := make([]string, len(v.Lines))
+	for i, l := range v.Lines {
+		lines[i] = fmt.Sprint(l)
+	}
+	return fmt.Sprintf("lines %s: %s", strings.Join(lines, ", "), v.Msg)
+}
+
+// ValidationError lists all violations of an input.
+type ValidationError struct {
+	Path       string
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s is not a closed rectilinear simple polygon:", e.Path)
+	for _, v := range e.Violations {
+		fmt.Fprintf(&sb, "\n  %s", v)
+	}
+	return sb.String()
+}
+
+// Validate checks that the red tiles form a closed rectilinear simple polygon: Each tile must be in the same row or
+// column as the next one, including the last and the first one, no tile may repeat, and the perimeter must neither
+// cross nor touch itself. Touching is only a warning.
+func Validate(points []*Point2D) []Violation {
+	if len(points) < 4 {
+		return []Violation{{Msg: fmt.Sprintf("expecting at least 4 red tiles, got %d", len(points))}}
+	}
+	var res []Violation
+	lines := make(map[Point2D]int)
+	repeated := make(map[polygon.Point]bool)
+	for i, p := range points {
+		if l, ok := lines[*p]; ok {
+			res = append(res, Violation{Lines: []int{l, i + 1}, Msg: fmt.Sprintf("red tile %s repeats", p)})
+			repeated[polygon.Point(*p)] = true
+			continue
+		}
+		lines[*p] = i + 1
+	}
+	rectilinear := true
+	for i, p := range points {
+		j := (i + 1) % len(points)
+		q := points[j]
+		if (p.X == q.X) == (p.Y == q.Y) {
+			rectilinear = false
+		}
+		if p.X == q.X || p.Y == q.Y {
+			continue
+		}
+		msg := fmt.Sprintf("red tiles %s and %s are neither in the same row nor in the same column", p, q)
+		if j == 0 {
+			msg += ", so the loop does not close"
+		}
+		res = append(res, Violation{Lines: []int{i + 1, j + 1}, Msg: msg})
+	}
+	if !rectilinear {
+		return res
+	}
+	poly, err := NewPolygon(points)
+	if err != nil {
+		return append(res, Violation{Msg: err.Error()})
+	}
+	for _, c := range poly.Contacts() {
+		// Repeated tiles are reported already.
+		if repeated[c.At] {
+			continue
+		}
+		kind := "touches"
+		if c.Crossing {
+			kind = "crosses"
+		}
+		var contactLines []int
+		for _, e := range c.Edges {
+			contactLines = append(contactLines, e+1, (e+1)%len(points)+1)
+		}
+		res = append(res, Violation{
+			Lines:   contactLines,
+			Msg:     fmt.Sprintf("the perimeter %s itself at %s", kind, c.At),
+			Warning: !c.Crossing,
+		})
+	}
+	return res
+}
+
+// LoadPolygon loads the red tiles and validates them. Warnings are written to warnings. If lenient, so are the other
+// violations, and the polygon is built anyway, if possible at all.
+func LoadPolygon(path string, lenient bool, warnings io.Writer) ([]*Point2D, *polygon.Polygon, error) {
+	points, err := LoadPoints(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	violations := Validate(points)
+	var errs []Violation
+	for _, v := range violations {
+		if !v.Warning {
+			errs = append(errs, v)
+		}
+	}
+	if len(errs) > 0 && !lenient {
+		return nil, nil, &ValidationError{Path: path, Violations: errs}
+	}
+	for _, v := range violations {
+		fmt.Fprintf(warnings, "warning: %s: %s\n", path, v)
+	}
+	poly, err := NewPolygon(points)
+	if err != nil {
+		return nil, nil, err
+	}
+	return points, poly, nil
+}
@@ -0,0 +1,165 @@
+package day12
+
+import "fmt"
+
+// Verdict tells whether a region can be packed.
+type Verdict int
+
+const (
+	Unknown Verdict = iota
+	Feasible
+	Infeasible
+)
+
+func (v Verdict) String() string {
+	switch v {
+	case Feasible:
+		return "feasible"
+	case Infeasible:
+		return "infeasible"
+	}
+	return "unknown"
+}
+
+// Analysis is the outcome of the cheap checks that run before any search.
+type Analysis struct {
+	Verdict Verdict
+	Reason  string
+	// Packing is set for feasible regions.
+	Packing *Attempt
+}
+
+// colourings assign +1 or -1 to each cell. Each of them is periodic with period 2 in both directions.
+var colourings = []struct {
+	name   string
+	colour func(x, y int) int
+}{
+	{"checkerboard", func(x, y int) int { return 1 - 2*((x+y)%2) }},
+	{"column", func(x, y int) int { return 1 - 2*(x%2) }},
+}
+
+// Analyze tries to decide the region without searching. It rejects regions that the shapes do not fit into by area or
+// by a colouring argument, and it accepts regions that have a disjoint bounding box for each shape.
+func Analyze(r *Region, shapes []*Shape) *Analysis {
+	area, shapeArea := r.width*r.height, r.ShapeArea(shapes)
+	if shapeArea > area {
+		return &Analysis{
+			Verdict: Infeasible,
+			Reason:  fmt.Sprintf("the shapes need %d cells, but the region has only %d", shapeArea, area),
+		}
+	}
+	for _, c := range colourings {
+		if reason, ok := refuteByColouring(r, shapes, c.colour); ok {
+			return &Analysis{
+				Verdict: Infeasible,
+				Reason:  fmt.Sprintf("%s colouring: %s", c.name, reason),
+			}
+		}
+	}
+	if a, reason := packIntoBoxes(r, shapes); a != nil {
+		return &Analysis{
+			Verdict: Feasible,
+			Reason:  reason,
+			Packing: a,
+		}
+	}
+	return &Analysis{Verdict: Unknown}
+}
+
+// refuteByColouring checks if the shapes can possibly cover the cells of each colour. Each placed shape covers some
+// more cells of one colour than of the other. If no combination of these imbalances leaves enough cells of both colours,
+// the region cannot be packed.
+func refuteByColouring(r *Region, shapes []*Shape, colour func(x, y int) int) (string, bool) {
+	var plus, minus int
+	for y := 0; y < r.height; y++ {
+		for x := 0; x < r.width; x++ {
+			if colour(x, y) > 0 {
+				plus++
+			} else {
+				minus++
+			}
+		}
+	}
+	// Collect the reachable imbalances D of all shapes. The shapes then cover (T+D)/2 plus cells and (T-D)/2 minus
+	// cells, where T is their total number of tiles.
+	reachable := map[int]bool{0: true}
+	for i, s := range shapes {
+		if i >= len(r.shapeCounts) || r.shapeCounts[i] == 0 {
+			continue
+		}
+		imbalances := make(map[int]bool)
+		for _, v := range s.Variants() {
+			for oy := 0; oy < 2; oy++ {
+				for ox := 0; ox < 2; ox++ {
+					var d int
+					for y, row := range v.mask {
+						for x, isSet := range row {
+							if isSet {
+								d += colour(ox+x, oy+y)
+							}
+						}
+					}
+					imbalances[d] = true
+				}
+			}
+		}
+		for n := 0; n < r.shapeCounts[i]; n++ {
+			next := make(map[int]bool)
+			for a := range reachable {
+				for d := range imbalances {
+					next[a+d] = true
+				}
+			}
+			reachable = next
+		}
+	}
+	tiles := r.ShapeArea(shapes)
+	for d := range reachable {
+		if tiles+d <= 2*plus && tiles-d <= 2*minus {
+			return "", false
+		}
+	}
+	return fmt.Sprintf("the shapes cannot be balanced across the %d and %d cells of either colour", plus, minus), true
+}
+
+// packIntoBoxes packs the region by giving each shape a bounding box of its own, laid out on a grid.
+func packIntoBoxes(r *Region, shapes []*Shape) (*Attempt, string) {
+	var count, boxWidth, boxHeight int
+	for i, s := range shapes {
+		if i >= len(r.shapeCounts) || r.shapeCounts[i] == 0 {
+			continue
+		}
+		count += r.shapeCounts[i]
+		boxWidth = max(boxWidth, s.width)
+		boxHeight = max(boxHeight, s.height)
+	}
+	if count == 0 {
+		return NewAttempt(r), "there are no shapes to place"
+	}
+	// The shapes may also be turned sideways if more boxes fit that way.
+	rotate := (r.width/boxHeight)*(r.height/boxWidth) > (r.width/boxWidth)*(r.height/boxHeight)
+	if rotate {
+		boxWidth, boxHeight = boxHeight, boxWidth
+	}
+	cols, rows := r.width/boxWidth, r.height/boxHeight
+	if cols*rows < count {
+		return nil, ""
+	}
+	a := NewAttempt(r)
+	slot := 0
+	for i, s := range shapes {
+		if i >= len(r.shapeCounts) {
+			continue
+		}
+		if rotate {
+			s = s.RotateCW()
+		}
+		for n := 0; n < r.shapeCounts[i]; n++ {
+			if a.Place(s, slot%cols*boxWidth, slot/cols*boxHeight) == nil {
+				panic(fmt.Sprintf("failed to place shape %d into box %d", i, slot))
+			}
+			slot++
+		}
+	}
+	return a, fmt.Sprintf("the %d shapes fit into %d disjoint %dx%d boxes", count, cols*rows, boxWidth, boxHeight)
+}
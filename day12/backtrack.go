@@ -0,0 +1,122 @@
+package day12
+
+// backtracker packs a region exactly by a depth-first search over shape placements.
+//
+// The search always decides the first undecided cell in row-major order: either some shape covers it, or it stays
+// empty for good. Any shape covering that cell must have its first tile there, because all cells before it are already
+// decided. That fixes the position of each shape variant and avoids enumerating the same packing in different orders.
+type backtracker struct {
+	attempt  *Attempt
+	variants [][]*Shape
+	firsts   [][]int // offset of the first tile in row-major order for each variant
+	skipped  [][]bool
+	// Cells that the pending shapes still need and cells that are still available for them.
+	pendingCells, freeCells int
+	stop                    <-chan struct{}
+}
+
+// solveBacktracking returns a complete packing of the region, or nil if there is none.
+func solveBacktracking(r *Region, shapes []*Shape, obs Observer, stop <-chan struct{}) (*Attempt, error) {
+	b := backtracker{
+		attempt:   NewAttempt(r),
+		freeCells: r.width * r.height,
+		stop:      stop,
+	}
+	b.attempt.Observe(obs)
+	b.skipped = make([][]bool, r.height)
+	for y := range b.skipped {
+		b.skipped[y] = make([]bool, r.width)
+	}
+	b.pendingCells = r.ShapeArea(shapes)
+	for _, s := range shapes {
+		vs := s.Variants()
+		b.variants = append(b.variants, vs)
+		var firsts []int
+		for _, v := range vs {
+			firsts = append(firsts, v.firstTile())
+		}
+		b.firsts = append(b.firsts, firsts)
+	}
+	ok, err := b.search(0)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return b.attempt, nil
+}
+
+func (b *backtracker) search(pos int) (bool, error) {
+	if b.pendingCells == 0 {
+		return true, nil
+	}
+	// Area bound: the pending shapes must fit into the cells that are still free.
+	if b.pendingCells > b.freeCells {
+		return false, nil
+	}
+	select {
+	case <-b.stop:
+		return false, errInterrupted
+	default:
+	}
+	r := b.attempt.region
+	// Find the first undecided cell.
+	for ; pos < r.width*r.height; pos++ {
+		x, y := pos%r.width, pos/r.width
+		if !b.skipped[y][x] && b.attempt.field[y][x] >= 0 {
+			break
+		}
+	}
+	if pos == r.width*r.height {
+		return false, nil
+	}
+	x, y := pos%r.width, pos/r.width
+	// Cover the cell with a shape.
+	for i, vs := range b.variants {
+		if b.attempt.shapeCounts[i] == 0 {
+			continue
+		}
+		for j, v := range vs {
+			sx, sy := x-b.firsts[i][j]%v.width, y-b.firsts[i][j]/v.width
+			if b.collidesWithSkipped(v, sx, sy) {
+				continue
+			}
+			if b.attempt.Place(v, sx, sy) == nil {
+				continue
+			}
+			size := v.Size()
+			b.pendingCells -= size
+			b.freeCells -= size
+			ok, err := b.search(pos + 1)
+			if ok || err != nil {
+				return ok, err
+			}
+			b.pendingCells += size
+			b.freeCells += size
+			b.attempt.Remove()
+		}
+	}
+	// Leave the cell empty.
+	b.skipped[y][x] = true
+	b.freeCells--
+	ok, err := b.search(pos + 1)
+	if ok || err != nil {
+		return ok, err
+	}
+	b.freeCells++
+	b.skipped[y][x] = false
+	return false, nil
+}
+
+func (b *backtracker) collidesWithSkipped(s *Shape, x, y int) bool {
+	r := b.attempt.region
+	for sy, row := range s.mask {
+		for sx, isSet := range row {
+			if !isSet || x+sx < 0 || y+sy < 0 || x+sx >= r.width || y+sy >= r.height {
+				continue
+			}
+			if b.skipped[y+sy][x+sx] {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package day12
+
+import (
+	"fmt"
+
+	"github.com/sekruse/adventofcode2025/dlx"
+)
+
+// solveDLX packs a region by reducing it to an exact cover problem. There is a primary column per shape that has to be
+// covered as often as the shape is required, and a secondary column per cell, which may stay empty.
+func solveDLX(r *Region, shapes []*Shape, obs Observer, stop <-chan struct{}) (*Attempt, error) {
+	// Dancing Links has no notion of area, so we check it ahead of time.
+	if r.ShapeArea(shapes) > r.width*r.height {
+		return nil, nil
+	}
+	m := dlx.New(len(shapes), r.width*r.height)
+	for i := range shapes {
+		var count int
+		if i < len(r.shapeCounts) {
+			count = r.shapeCounts[i]
+		}
+		m.SetMultiplicity(i, count)
+	}
+	var placements []*ShapePlacement
+	for i, s := range shapes {
+		for _, v := range s.Variants() {
+			for y := 0; y+v.height <= r.height; y++ {
+				for x := 0; x+v.width <= r.width; x++ {
+					cols := []int{i}
+					for sy, row := range v.mask {
+						for sx, isSet := range row {
+							if isSet {
+								cols = append(cols, len(shapes)+(y+sy)*r.width+x+sx)
+							}
+						}
+					}
+					m.AddRow(cols...)
+					placements = append(placements, &ShapePlacement{shape: v, x: x, y: y})
+				}
+			}
+		}
+	}
+	// The attempt follows the search, so that the observer sees each placement and removal.
+	attempt := NewAttempt(r)
+	attempt.Observe(obs)
+	var found bool
+	var err error
+	m.SetTrace(func(row int, selected bool) {
+		if found || err != nil {
+			return
+		}
+		if !selected {
+			attempt.Remove()
+			return
+		}
+		sp := placements[row]
+		if attempt.Place(sp.shape, sp.x, sp.y) == nil {
+			err = fmt.Errorf("dlx search places shape %d at (%d, %d) illegally", sp.shape.index, sp.x, sp.y)
+		}
+	})
+	for range m.Solutions(stop) {
+		// The search takes back its rows on the way out, which the attempt should not follow.
+		found = true
+		break
+	}
+	switch {
+	case err != nil:
+		return nil, err
+	case found:
+		return attempt, nil
+	case m.Interrupted():
+		return nil, errInterrupted
+	}
+	return nil, nil
+}
@@ -3,190 +3,158 @@ package day12
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"regexp"
+	"runtime"
 	"slices"
 	"strconv"
 	"strings"
 	"time"
 
-	"github.com/gdamore/tcell/v3"
+	"github.com/sekruse/adventofcode2025/polyomino"
 )
 
 var (
-	defStyle    = tcell.StyleDefault.Background(tcell.ColorReset).Foreground(tcell.ColorReset)
-	shapeStyles = []tcell.Style{
-		defStyle.Background(tcell.ColorBlue).Foreground(tcell.ColorWhite),
-		defStyle.Background(tcell.ColorRed).Foreground(tcell.ColorWhite),
-		defStyle.Background(tcell.ColorGreen).Foreground(tcell.ColorWhite),
-		defStyle.Background(tcell.ColorViolet).Foreground(tcell.ColorWhite),
-		defStyle.Background(tcell.ColorYellow).Foreground(tcell.ColorBlack),
-		defStyle.Background(tcell.ColorWhite).Foreground(tcell.ColorBlack),
-	}
 	shapeMarkers = []rune{'A', 'B', 'C', 'D', 'E', 'F', 'G', 'H', 'I', 'J', 'K', 'L', 'M', 'N', 'O', 'P', 'Q', 'R', 'S', 'T', 'U', 'V', 'W', 'X', 'Y', 'Z'}
+
+	errInterrupted = fmt.Errorf("interrupted")
 )
 
-func Round1(path string, verbose bool) (int, error) {
-	shapes, regions, err := LoadData(path)
-	if err != nil {
-		return 0, err
+// Solver finds a complete packing of the region, or returns nil if there is none. The observer, if not nil, is notified
+// about every placement the solver tries. The search is aborted with errInterrupted once stop is closed.
+type Solver func(r *Region, shapes []*Shape, obs Observer, stop <-chan struct{}) (*Attempt, error)
+
+// Solvers lists the available packing algorithms by name.
+var Solvers = map[string]Solver{
+	"backtracking": solveBacktracking,
+	"dlx":          solveDLX,
+	"sat":          solveSAT,
+}
+
+// Options configures how Round1 solves the regions.
+type Options struct {
+	// Solver is the name of the packing algorithm in Solvers. Empty means backtracking.
+	Solver string
+	// DIMACSDir is a directory to write the SAT encoding of each region to, so that it can be checked with other
+	// solvers. Empty means no export.
+	DIMACSDir string
+	// Visualize records the search on each region and lets the user step through it on the terminal afterwards.
+	// Otherwise, Round1 runs headless and only prints the packings as text when verbose.
+	Visualize bool
+	// SolutionsPath is a file to write the layout of each feasible region to, see Validate. Empty means no export.
+	SolutionsPath string
+	// Workers is the number of regions to solve concurrently. Zero means one per CPU.
+	Workers int
+	// Timeout is the time budget for the search on each region. Regions that run out of time get an unknown verdict.
+	// Zero means no limit.
+	Timeout time.Duration
+	// CountUnknown counts the regions with an unknown verdict as feasible. Otherwise, only proven packings count.
+	CountUnknown bool
+}
+
+func Round1(path string, opts Options, verbose bool) (int, error) {
+	solverName := opts.Solver
+	if solverName == "" {
+		solverName = "backtracking"
 	}
-	screen, err := tcell.NewScreen()
-	if err != nil {
-		return 0, err
+	solve, ok := Solvers[solverName]
+	if !ok {
+		return 0, fmt.Errorf("unknown solver %q", opts.Solver)
 	}
-	defer screen.Fini()
-	if err := screen.Init(); err != nil {
+	shapes, regions, err := LoadData(path)
+	if err != nil {
 		return 0, err
 	}
-	stop := make(chan struct{})
-	go func() {
-		for {
-			ev := <-screen.EventQ()
-			switch ev := ev.(type) {
-			case *tcell.EventKey:
-				if ev.Key() == tcell.KeyEscape || ev.Key() == tcell.KeyCtrlC {
-					stop <- struct{}{}
-					close(stop)
-					return
-				}
-			}
-		}
-	}()
-	var res int
-	for _, reg := range regions {
-		if solve(reg, shapes, screen, stop) {
-			res++
+	var renderer Renderer = NoopRenderer{}
+	var screen *ScreenRenderer
+	switch {
+	case opts.Visualize:
+		screen, err = NewScreenRenderer()
+		if err != nil {
+			return 0, err
 		}
-		select {
-		case <-stop:
-			return 0, fmt.Errorf("interrupted")
-		case <-time.After(100 * time.Millisecond):
+		renderer = screen
+	case verbose:
+		renderer = NewTextRenderer(os.Stdout)
+	}
+	defer renderer.Close()
+	if opts.DIMACSDir != "" {
+		if err := writeDIMACS(opts.DIMACSDir, shapes, regions); err != nil {
+			return 0, err
 		}
 	}
-	return res, nil
-}
-
-func solve(r *Region, shapes []*Shape, screen tcell.Screen, stop <-chan struct{}) bool {
-	var shapeVariants [][]*Shape
-	for _, s := range shapes {
-		shapeVariants = append(shapeVariants, s.Variants())
-	}
-	attempt := NewAttempt(r)
-	var round int
-	for {
-		round++
-		// List shapes that could be added.
-		var pendingShapes []*Shape
-		for i, c := range attempt.shapeCounts {
-			if c > 0 {
-				pendingShapes = append(pendingShapes, shapeVariants[i]...)
-			}
+	var solutions io.Writer = io.Discard
+	if opts.SolutionsPath != "" {
+		file, err := os.Create(opts.SolutionsPath)
+		if err != nil {
+			return 0, err
+		}
+		defer file.Close()
+		w := bufio.NewWriter(file)
+		defer w.Flush()
+		solutions = w
+	}
+	rs := &regionSolver{
+		shapes:     shapes,
+		solve:      solve,
+		solverName: solverName,
+		workers:    opts.Workers,
+		timeout:    opts.Timeout,
+		record:     screen != nil,
+	}
+	if rs.workers <= 0 {
+		rs.workers = runtime.NumCPU()
+	}
+	var summary Summary
+	var recordings []*Recording
+	for res, err := range rs.solveAll(regions, renderer.Interrupted()) {
+		if err != nil {
+			return 0, err
 		}
-		if len(pendingShapes) == 0 {
-			return true
+		summary.Add(res)
+		if res.Recording != nil {
+			recordings = append(recordings, res.Recording)
 		}
-		// Find possible placements for the shapes.
-		var possiblePlacements []*ShapePlacement
-		for _, shape := range pendingShapes {
-			Rows:
-			for x := 0; x < r.width; x++ {
-				for y := 0; y < r.height; y++ {
-					sp := attempt.Place(shape, x, y)
-					if sp == nil {
-						continue
-					}
-					// if !drawAndPause(attempt, fmt.Sprintf("Round %d", round), screen, 100 * time.Millisecond, stop) {
-					// 	return false
-					// }
-					attempt.Remove()
-					possiblePlacements = append(possiblePlacements, sp)
-					if sp.overlap > 0 {
-						continue
-					}
-					if y == 0 {
-						break Rows
-					}
-					break
-				}
+		attempt := res.Packing
+		if attempt != nil {
+			if err := writeSolution(solutions, res.Index, attempt); err != nil {
+				return 0, err
 			}
+		} else {
+			attempt = NewAttempt(res.Region)
 		}
-		if len(possiblePlacements) == 0 {
-			break
-		}
-		// Pick a placement that maximizes overlap.
-		slices.SortFunc(possiblePlacements, func(a, b *ShapePlacement) int {
-			return b.overlap - a.overlap
-		})
-		sp := possiblePlacements[0]
-		if test := attempt.Place(sp.shape, sp.x, sp.y); test == nil {
-			panic(fmt.Sprintf("failed to place shape %d at (%d, %d)", sp.shape.index, sp.x, sp.y))
+		status := fmt.Sprintf("Region %d of %d: %s by %s in %s", res.Index+1, len(regions), res.Verdict, res.Reason,
+			res.Elapsed.Round(time.Microsecond))
+		if !renderer.Render(attempt, status) {
+			return 0, errInterrupted
 		}
-		// if !drawAndPause(attempt, fmt.Sprintf("Round %d: Done", round), screen, 0 * time.Millisecond, stop) {
-		//	return false
-		// }
 	}
-	if !drawAndPause(attempt, fmt.Sprintf("Round %d: Done", round), screen, 0 * time.Millisecond, stop) {
-		return false
-	 }
-	return false
-}
-
-func drawAndPause(attempt *Attempt, status string, screen tcell.Screen, pause time.Duration, stop <-chan struct{}) (ok bool) {
-	// Draw the attempt.
-	screen.Clear()
-	r := attempt.region
-	drawCanvas(screen, r.width, r.height, defStyle)
-	for i, sp := range attempt.placedShapes {
-		drawShape(screen, sp.x, sp.y, sp.shape, shapeMarkers[i%len(shapeMarkers)], shapeStyles[i%len(shapeStyles)])
-	}
-	drawText(screen, 0, r.height+3, 80, fmt.Sprintf("% d", attempt.shapeCounts), defStyle)
-	drawText(screen, 0, r.height+4, 80, status, defStyle)
-	screen.Show()
-	// Loop handling.
-	select {
-	case <-stop:
-		return false
-	case <-time.After(pause):
-		return true
-	}
-}
-func drawCanvas(screen tcell.Screen, width, height int, style tcell.Style) {
-	screen.Put(0, 0, string(tcell.RuneULCorner), style)
-	screen.Put(width+1, 0, string(tcell.RuneURCorner), style)
-	screen.Put(0, height+1, string(tcell.RuneLLCorner), style)
-	screen.Put(width+1, height+1, string(tcell.RuneLRCorner), style)
-	for x := 1; x <= width; x++ {
-		screen.Put(x, 0, string(tcell.RuneHLine), style)
-		screen.Put(x, height+1, string(tcell.RuneHLine), style)
-	}
-	for y := 1; y <= height; y++ {
-		screen.Put(0, y, string(tcell.RuneVLine), style)
-		screen.Put(width+1, y, string(tcell.RuneVLine), style)
-	}
-}
-
-func drawShape(screen tcell.Screen, ox, oy int, shape *Shape, r rune, style tcell.Style) {
-	for y, row := range shape.mask {
-		for x, set := range row {
-			if set {
-				screen.Put(1+ox+x, 1+oy+y, string(r), style)
-			}
+	if verbose && screen == nil {
+		fmt.Println(summary.String())
+		if summary.Unknown > 0 && opts.CountUnknown {
+			fmt.Printf("Counting the %d unknown verdicts as feasible\n", summary.Unknown)
 		}
 	}
-}
-
-func drawText(screen tcell.Screen, ox, oy, width int, text string, style tcell.Style) {
-	for i, r := range text {
-		screen.Put(ox+i%width, oy+i/width, string(r), style)
+	if screen != nil {
+		if err := NewPlayer(screen, recordings).Run(); err != nil {
+			return 0, err
+		}
 	}
+	return summary.Count(opts.CountUnknown), nil
 }
 
 type Attempt struct {
 	placedShapes []*ShapePlacement
 	shapeCounts  []int
-	field        [][]int  // counts number of shapes on cell, negative if one shape has a actual tile there
+	field        [][]int // counts number of shapes on cell, negative if one shape has an actual tile there
 	region       *Region
+	observer     Observer
+}
+
+// Observe sets the observer that is notified about each placement and removal. It may be nil.
+func (a *Attempt) Observe(obs Observer) {
+	a.observer = obs
 }
 
 func NewAttempt(r *Region) *Attempt {
@@ -201,6 +169,17 @@ func NewAttempt(r *Region) *Attempt {
 	return &attempt
 }
 
+// String draws the field as in Layout, falling back to cycling through the letters if they do not suffice.
+func (a *Attempt) String() string {
+	rows, err := a.Layout()
+	if err != nil {
+		rows = a.layout(func(i int, _ []bool) rune {
+			return shapeMarkers[i%len(shapeMarkers)]
+		})
+	}
+	return strings.Join(rows, "\n") + "\n"
+}
+
 // Place attempts to place the given shape at the given position.
 func (a *Attempt) Place(s *Shape, x, y int) *ShapePlacement {
 	// Check shape supply.
@@ -214,7 +193,7 @@ func (a *Attempt) Place(s *Shape, x, y int) *ShapePlacement {
 	// Check overlap.
 	for sy, row := range s.mask {
 		for sx, isSet := range row {
-			if isSet && a.field[y+sy][x+sx] < 0{
+			if isSet && a.field[y+sy][x+sx] < 0 {
 				return nil
 			}
 		}
@@ -231,14 +210,22 @@ func (a *Attempt) Place(s *Shape, x, y int) *ShapePlacement {
 			if cell != 0 {
 				sp.overlap++
 			}
-			a.field[y+sy][x+sx] = cell + 1
-			if isSet {
-				a.field[y+sy][x+sx] *= -1
+			switch {
+			case isSet:
+				cell = -(cell + 1)
+			case cell < 0:
+				cell--
+			default:
+				cell++
 			}
+			a.field[y+sy][x+sx] = cell
 		}
 	}
 	a.placedShapes = append(a.placedShapes, &sp)
 	a.shapeCounts[s.index]--
+	if a.observer != nil {
+		a.observer.Placed(a, &sp)
+	}
 	return &sp
 }
 
@@ -251,19 +238,28 @@ func (a *Attempt) Remove() bool {
 	a.placedShapes = a.placedShapes[:len(a.placedShapes)-1]
 	for sy, row := range sp.shape.mask {
 		for sx, isSet := range row {
-			if isSet {
-				a.field[sp.y+sy][sp.x+sx] *= -1
+			cell := a.field[sp.y+sy][sp.x+sx]
+			switch {
+			case isSet:
+				cell = -cell - 1
+			case cell < 0:
+				cell++
+			default:
+				cell--
 			}
-			a.field[sp.y+sy][sp.x+sx] -= 1
+			a.field[sp.y+sy][sp.x+sx] = cell
 		}
 	}
 	a.shapeCounts[sp.shape.index]++
+	if a.observer != nil {
+		a.observer.Removed(a, sp)
+	}
 	return true
 }
 
 type ShapePlacement struct {
-	shape *Shape
-	x, y  int
+	shape   *Shape
+	x, y    int
 	overlap int
 }
 
@@ -273,34 +269,29 @@ type Shape struct {
 	index         int
 }
 
-func (s *Shape) Equals(t *Shape) bool {
-	if s.index!= t.index || s.width != t.width || s.height != t.height {
-		return false
-	}
-	for y, row := range s.mask {
-		for x, cell := range row {
-			if cell != t.mask[y][x] {
-				return false
+// Size returns the number of tiles of the shape.
+func (s *Shape) Size() int {
+	var res int
+	for _, row := range s.mask {
+		for _, cell := range row {
+			if cell {
+				res++
 			}
 		}
 	}
-	return true
+	return res
 }
 
-func (s *Shape) FlipLR() *Shape {
-	t := &Shape{
-		width:  s.width,
-		height: s.height,
-		index:  s.index,
-	}
-	t.mask = make([][]bool, t.height)
+// firstTile returns the row-major offset of the first tile of the shape.
+func (s *Shape) firstTile() int {
 	for y, row := range s.mask {
-		t.mask[y] = make([]bool, t.width)
 		for x, cell := range row {
-			t.mask[y][t.width-x-1] = cell
+			if cell {
+				return y*s.width + x
+			}
 		}
 	}
-	return t
+	return -1
 }
 
 func (s *Shape) RotateCW() *Shape {
@@ -319,24 +310,18 @@ func (s *Shape) RotateCW() *Shape {
 	return t
 }
 
+// Polyomino returns the tiles of the shape as a polyomino.
+func (s *Shape) Polyomino() polyomino.Polyomino {
+	return polyomino.FromMask(s.mask)
+}
+
+// Variants returns the distinct rotations and flips of the shape, starting with the shape itself. Empty rows and
+// columns around the tiles are trimmed.
 func (s *Shape) Variants() []*Shape {
-	res := []*Shape{s}
-	// Rotate shape. A rotational symmetry of 90 degrees implies a rotational symmetry of 180 degrees.
-	t := s
-	for i := 0; i < 3; i++ {
-		t = t.RotateCW()
-		if t.Equals(s) {
-			break
-		}
-		res = append(res, t)
-	}
-	// Every flip (horizontally, diagonally, vertically) can be created from a horizontal flip and a rotation.
-	t = s.FlipLR()
-	for i := 0; i < 4; i++ {
-		if !t.Equals(s) {
-			res = append(res, t)
-		}
-		t = t.RotateCW()
+	var res []*Shape
+	for _, p := range s.Polyomino().Variants() {
+		w, h := p.Bounds()
+		res = append(res, &Shape{mask: p.Mask(), width: w, height: h, index: s.index})
 	}
 	return res
 }
@@ -346,6 +331,17 @@ type Region struct {
 	shapeCounts   []int
 }
 
+// ShapeArea returns the number of cells that the required shapes cover in total.
+func (r *Region) ShapeArea(shapes []*Shape) int {
+	var res int
+	for i, s := range shapes {
+		if i < len(r.shapeCounts) {
+			res += r.shapeCounts[i] * s.Size()
+		}
+	}
+	return res
+}
+
 var (
 	regionRE = regexp.MustCompile(`^(\d+)x(\d+):\s+((?:\d+ ?)+)$`)
 )
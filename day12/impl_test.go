@@ -1,20 +1,183 @@
 package day12
 
 import (
+	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestResults(t *testing.T) {
-	t.Run("Round 1", func(t *testing.T) {
-		testFilePath := filepath.Join("testdata", "example.txt")
-		const want = 2
-		got, err := Round1(testFilePath, false)
+	// The SAT solver takes seconds to refute the third example region, so TestSAT refutes smaller ones.
+	for _, solver := range []string{"backtracking", "dlx"} {
+		t.Run("Round 1 with "+solver, func(t *testing.T) {
+			testFilePath := filepath.Join("testdata", "example.txt")
+			const want = 2
+			got, err := Round1(testFilePath, Options{Solver: solver}, false)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != want {
+				t.Errorf("got %d, want %d", got, want)
+			}
+		})
+	}
+}
+
+func TestSAT(t *testing.T) {
+	shapes, regions, err := LoadData(filepath.Join("testdata", "example.txt"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for i, tc := range []struct {
+		region   *Region
+		feasible bool
+	}{
+		{regions[0], true},
+		{regions[1], true},
+		// The shapes fit by area, but not by form.
+		{&Region{width: 4, height: 4, shapeCounts: []int{0, 0, 0, 0, 0, 2}}, false},
+		{&Region{width: 5, height: 5, shapeCounts: []int{0, 0, 0, 1, 1, 1}}, false},
+	} {
+		got, err := solveSAT(tc.region, shapes, nil, nil)
 		if err != nil {
 			t.Fatalf("Unexpected error: %v", err)
 		}
-		if got != want {
-			t.Errorf("got %d, want %d", got, want)
+		if (got != nil) != tc.feasible {
+			t.Errorf("region %d: got packing %v, want %v", i, got != nil, tc.feasible)
+			continue
+		}
+		if got == nil {
+			continue
+		}
+		for j, c := range got.shapeCounts {
+			if c != 0 {
+				t.Errorf("region %d: %d shapes of type %d left", i, c, j)
+			}
+		}
+	}
+}
+
+func TestRecording(t *testing.T) {
+	shapes, regions, err := LoadData(filepath.Join("testdata", "example.txt"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, name := range []string{"backtracking", "dlx", "sat"} {
+		rec := NewRecording(1, regions[1])
+		got, err := Solvers[name](regions[1], shapes, rec, nil)
+		if err != nil || got == nil {
+			t.Fatalf("%s: got %v, %v, want a packing", name, got, err)
+		}
+		if rec.backtracks == 0 {
+			t.Errorf("%s: got no backtracks, want the steps of the search", name)
+		}
+		// Replaying the recording as the player does ends on the packing.
+		replay := NewAttempt(regions[1])
+		for _, e := range rec.Events {
+			if e.Kind == EventPlace {
+				replay.Place(e.Placement.shape, e.Placement.x, e.Placement.y)
+			} else {
+				replay.Remove()
+			}
+		}
+		if replay.String() != got.String() {
+			t.Errorf("%s: got replay\n%s, want\n%s", name, replay, got)
 		}
-	})
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	shapes, regions, err := LoadData(filepath.Join("testdata", "example.txt"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// The search on the third region never finishes, the others finish right away.
+	solve := func(r *Region, shapes []*Shape, obs Observer, stop <-chan struct{}) (*Attempt, error) {
+		if r != regions[2] {
+			return NewAttempt(r), nil
+		}
+		<-stop
+		return nil, errInterrupted
+	}
+	rs := &regionSolver{shapes: shapes, solve: solve, solverName: "stub", workers: 3, timeout: time.Millisecond}
+	var got Summary
+	var indexes []int
+	for res, err := range rs.solveAll(regions, nil) {
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		got.Add(res)
+		indexes = append(indexes, res.Index)
+	}
+	want := Summary{Feasible: 2, Unknown: 1}
+	if got != want {
+		t.Errorf("got %v, want %v", &got, &want)
+	}
+	if !slices.Equal(indexes, []int{0, 1, 2}) {
+		t.Errorf("got results for regions %v, want them in order", indexes)
+	}
+	if got.Count(false) != 2 || got.Count(true) != 3 {
+		t.Errorf("got counts %d and %d, want 2 without and 3 with the unknown verdicts", got.Count(false), got.Count(true))
+	}
+}
+
+func TestSolutions(t *testing.T) {
+	testFilePath := filepath.Join("testdata", "example.txt")
+	solutionsPath := filepath.Join(t.TempDir(), "solutions.txt")
+	if _, err := Round1(testFilePath, Options{SolutionsPath: solutionsPath}, false); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	got, err := Validate(testFilePath, solutionsPath)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	const want = 2
+	if got != want {
+		t.Errorf("got %d valid layouts, want %d", got, want)
+	}
+	// Drop a tile, so that a shape is not a variant anymore and the counts are off.
+	layout, err := os.ReadFile(solutionsPath)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	broken := strings.Replace(string(layout), "AAA.", "AA..", 1)
+	if err := os.WriteFile(solutionsPath, []byte(broken), 0o644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got, err := Validate(testFilePath, solutionsPath); err == nil || got != 1 {
+		t.Errorf("got %d valid layouts and error %v, want 1 and an error", got, err)
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	tetromino := &Shape{
+		mask: [][]bool{
+			{true, true, true},
+			{false, true, false},
+		},
+		width:  3,
+		height: 2,
+	}
+	shapes := []*Shape{tetromino}
+	for _, tc := range []struct {
+		name   string
+		region *Region
+		want   Verdict
+	}{
+		{"Area", &Region{width: 4, height: 4, shapeCounts: []int{5}}, Infeasible},
+		// Each T covers 3 cells of one colour and 1 of the other, so an odd number of them cannot balance the colours.
+		{"Checkerboard", &Region{width: 4, height: 5, shapeCounts: []int{5}}, Infeasible},
+		{"Boxes", &Region{width: 6, height: 4, shapeCounts: []int{4}}, Feasible},
+		{"Undecided", &Region{width: 4, height: 4, shapeCounts: []int{4}}, Unknown},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := Analyze(tc.region, shapes)
+			if got.Verdict != tc.want {
+				t.Errorf("got %s (%s), want %s", got.Verdict, got.Reason, tc.want)
+			}
+		})
+	}
 }
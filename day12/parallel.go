@@ -0,0 +1,170 @@
+package day12
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Result is the verdict on a single region. The verdict is Unknown if the search ran out of time.
+type Result struct {
+	Index   int
+	Region  *Region
+	Verdict Verdict
+	Reason  string
+	// Packing is set for feasible regions.
+	Packing *Attempt
+	// Recording is set if the search was recorded.
+	Recording *Recording
+	Elapsed   time.Duration
+}
+
+// Summary counts the verdicts on all regions.
+type Summary struct {
+	Feasible, Infeasible, Unknown int
+}
+
+func (s *Summary) Add(r *Result) {
+	switch r.Verdict {
+	case Feasible:
+		s.Feasible++
+	case Infeasible:
+		s.Infeasible++
+	default:
+		s.Unknown++
+	}
+}
+
+// Count returns the number of feasible regions. Regions that ran out of time passed the area and colouring checks, so
+// countUnknown may count them as feasible by heuristic.
+func (s *Summary) Count(countUnknown bool) int {
+	if countUnknown {
+		return s.Feasible + s.Unknown
+	}
+	return s.Feasible
+}
+
+func (s *Summary) String() string {
+	return fmt.Sprintf("%d proven verdicts (%d feasible, %d infeasible), %d unknown verdicts (timed out)",
+		s.Feasible+s.Infeasible, s.Feasible, s.Infeasible, s.Unknown)
+}
+
+// regionSolver solves regions concurrently.
+type regionSolver struct {
+	shapes     []*Shape
+	solve      Solver
+	solverName string
+	workers    int
+	// timeout is the time budget for the search on each region. Zero means no limit.
+	timeout time.Duration
+	record  bool
+}
+
+// solveAll solves the regions on a pool of workers and yields the results in region order. The searches are aborted
+// with errInterrupted once stop is closed. Breaking out of the iteration stops all workers.
+func (rs *regionSolver) solveAll(regions []*Region, stop <-chan struct{}) iter.Seq2[*Result, error] {
+	return func(yield func(*Result, error) bool) {
+		type outcome struct {
+			index int
+			res   *Result
+			err   error
+		}
+		done := make(chan struct{})
+		jobs := make(chan int)
+		// The buffer lets workers finish even if nobody receives the outcomes anymore.
+		outcomes := make(chan outcome, len(regions))
+		var wg sync.WaitGroup
+		defer wg.Wait()
+		defer close(done)
+		for range max(rs.workers, 1) {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range jobs {
+					res, err := rs.solveRegion(i, regions[i], stop, done)
+					outcomes <- outcome{i, res, err}
+				}
+			}()
+		}
+		go func() {
+			defer close(jobs)
+			for i := range regions {
+				select {
+				case jobs <- i:
+				case <-done:
+					return
+				}
+			}
+		}()
+		pending := make(map[int]outcome)
+		for next := 0; next < len(regions); {
+			o := <-outcomes
+			pending[o.index] = o
+			for p, ok := pending[next]; ok; p, ok = pending[next] {
+				delete(pending, next)
+				next++
+				if !yield(p.res, p.err) || p.err != nil {
+					return
+				}
+			}
+		}
+	}
+}
+
+// solveRegion runs the cheap checks on the region and then searches within the time budget.
+func (rs *regionSolver) solveRegion(index int, r *Region, stop, done <-chan struct{}) (*Result, error) {
+	start := time.Now()
+	res := &Result{Index: index, Region: r}
+	var obs Observer
+	if rs.record {
+		res.Recording = NewRecording(index, r)
+		obs = res.Recording
+	}
+	analysis := Analyze(r, rs.shapes)
+	res.Verdict, res.Reason, res.Packing = analysis.Verdict, analysis.Reason, analysis.Packing
+	if analysis.Verdict == Unknown {
+		var timedOut atomic.Bool
+		regionStop, finished := make(chan struct{}), make(chan struct{})
+		go func() {
+			var expired <-chan time.Time
+			if rs.timeout > 0 {
+				timer := time.NewTimer(rs.timeout)
+				defer timer.Stop()
+				expired = timer.C
+			}
+			select {
+			case <-stop:
+			case <-done:
+			case <-expired:
+				timedOut.Store(true)
+			case <-finished:
+				return
+			}
+			close(regionStop)
+		}()
+		attempt, err := rs.solve(r, rs.shapes, obs, regionStop)
+		close(finished)
+		switch {
+		case errors.Is(err, errInterrupted) && timedOut.Load():
+			res.Reason = fmt.Sprintf("%s search running out of time after %s", rs.solverName, rs.timeout)
+		case err != nil:
+			return nil, err
+		case attempt != nil:
+			res.Verdict, res.Reason, res.Packing = Feasible, fmt.Sprintf("%s search", rs.solverName), attempt
+		default:
+			res.Verdict, res.Reason = Infeasible, fmt.Sprintf("%s search", rs.solverName)
+		}
+	} else if obs != nil && res.Packing != nil {
+		for _, sp := range res.Packing.placedShapes {
+			obs.Placed(res.Packing, sp)
+		}
+	}
+	if res.Recording != nil {
+		res.Recording.Verdict, res.Recording.Reason = res.Verdict, res.Reason
+	}
+	res.Elapsed = time.Since(start)
+	return res, nil
+}
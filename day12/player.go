@@ -0,0 +1,172 @@
+package day12
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gdamore/tcell/v3"
+)
+
+// playSpeeds are the delays between two frames and the number of events per frame, from slowest to fastest.
+var playSpeeds = []struct {
+	delay time.Duration
+	steps int
+}{
+	{500 * time.Millisecond, 1},
+	{200 * time.Millisecond, 1},
+	{100 * time.Millisecond, 1},
+	{50 * time.Millisecond, 1},
+	{20 * time.Millisecond, 1},
+	{10 * time.Millisecond, 1},
+	{10 * time.Millisecond, 10},
+	{10 * time.Millisecond, 100},
+	{10 * time.Millisecond, 1000},
+}
+
+const playerHelp = "Space: play/pause, Left/Right: step, Up/Down: speed, PgUp/PgDn: region, Home/End: start/end, q: quit"
+
+// Player replays recorded searches on the terminal and lets the user step through them.
+type Player struct {
+	screen     *ScreenRenderer
+	recordings []*Recording
+	region     int // index into recordings
+	pos        int // number of events applied to attempt
+	attempt    *Attempt
+	playing    bool
+	speed      int // index into playSpeeds
+}
+
+func NewPlayer(screen *ScreenRenderer, recordings []*Recording) *Player {
+	return &Player{
+		screen:     screen,
+		recordings: recordings,
+		speed:      3,
+	}
+}
+
+// Run shows the recordings until the user quits.
+func (p *Player) Run() error {
+	if len(p.recordings) == 0 {
+		return nil
+	}
+	p.jump(0)
+	for {
+		p.draw()
+		var tick <-chan time.Time
+		if p.playing {
+			tick = time.After(playSpeeds[p.speed].delay)
+		}
+		select {
+		case <-p.screen.Interrupted():
+			return nil
+		case ev := <-p.screen.Keys():
+			if !p.handle(ev) {
+				return nil
+			}
+		case <-tick:
+			for i := 0; i < playSpeeds[p.speed].steps; i++ {
+				if !p.forward() {
+					p.playing = false
+					break
+				}
+			}
+		}
+	}
+}
+
+// handle reacts to a key press and returns false if the user wants to quit.
+func (p *Player) handle(ev *tcell.EventKey) bool {
+	switch ev.Key() {
+	case tcell.KeyRight:
+		p.playing = false
+		p.forward()
+	case tcell.KeyLeft:
+		p.playing = false
+		p.back()
+	case tcell.KeyUp:
+		p.speed = min(p.speed+1, len(playSpeeds)-1)
+	case tcell.KeyDown:
+		p.speed = max(p.speed-1, 0)
+	case tcell.KeyPgDn:
+		p.jump(p.region + 1)
+	case tcell.KeyPgUp:
+		p.jump(p.region - 1)
+	case tcell.KeyHome:
+		p.jump(p.region)
+	case tcell.KeyEnd:
+		for p.forward() {
+		}
+		p.playing = false
+	case tcell.KeyRune:
+		switch ev.Str() {
+		case " ":
+			p.playing = !p.playing
+		case "q":
+			return false
+		}
+	}
+	return true
+}
+
+// jump starts over with the given region.
+func (p *Player) jump(region int) {
+	p.region = min(max(region, 0), len(p.recordings)-1)
+	p.attempt = NewAttempt(p.recordings[p.region].Region)
+	p.pos = 0
+}
+
+// forward applies the next event and returns false if there is none.
+func (p *Player) forward() bool {
+	events := p.recordings[p.region].Events
+	if p.pos >= len(events) {
+		return false
+	}
+	e := events[p.pos]
+	switch e.Kind {
+	case EventPlace:
+		p.attempt.Place(e.Placement.shape, e.Placement.x, e.Placement.y)
+	case EventRemove:
+		p.attempt.Remove()
+	}
+	p.pos++
+	return true
+}
+
+// back reverts the last applied event and returns false if there is none.
+func (p *Player) back() bool {
+	if p.pos == 0 {
+		return false
+	}
+	p.pos--
+	e := p.recordings[p.region].Events[p.pos]
+	switch e.Kind {
+	case EventPlace:
+		p.attempt.Remove()
+	case EventRemove:
+		// The search removes shapes in reverse order of placement, so the removed shape goes back on top.
+		p.attempt.Place(e.Placement.shape, e.Placement.x, e.Placement.y)
+	}
+	return true
+}
+
+func (p *Player) draw() {
+	rec := p.recordings[p.region]
+	var backtracks int
+	if p.pos > 0 {
+		backtracks = rec.Events[p.pos-1].Backtracks
+	}
+	verdict := rec.Verdict.String() + " by " + rec.Reason
+	if rec.Truncated {
+		verdict += ", recording truncated"
+	}
+	state := "paused"
+	if p.playing {
+		state = fmt.Sprintf("playing %d step(s) per %s", playSpeeds[p.speed].steps, playSpeeds[p.speed].delay)
+	}
+	status := fmt.Sprintf("Region %d/%d (%dx%d, %s). Event %d/%d, depth %d, backtracks %d, %s.",
+		p.region+1, len(p.recordings), rec.Region.width, rec.Region.height, verdict,
+		p.pos, len(rec.Events), len(p.attempt.placedShapes), backtracks, state)
+	line := p.screen.draw(p.attempt, status)
+	drawText(p.screen.screen, 0, line, 120, playerHelp, defStyle)
+	p.screen.screen.Show()
+}
@@ -0,0 +1,61 @@
+package day12
+
+// maxRecordedEvents bounds the memory that a recording may take up.
+const maxRecordedEvents = 1 << 20
+
+// Observer is notified about every change to an attempt.
+type Observer interface {
+	Placed(a *Attempt, sp *ShapePlacement)
+	Removed(a *Attempt, sp *ShapePlacement)
+}
+
+type EventKind int
+
+const (
+	EventPlace EventKind = iota
+	EventRemove
+)
+
+// Event is a single step of a search.
+type Event struct {
+	Kind      EventKind
+	Placement *ShapePlacement
+	// Backtracks counts the removals up to and including this event.
+	Backtracks int
+}
+
+// Recording keeps the events of a search on one region, so that it can be replayed.
+type Recording struct {
+	Index      int
+	Region     *Region
+	Events     []Event
+	Verdict    Verdict
+	Reason     string // how the verdict was reached
+	Truncated  bool
+	backtracks int
+}
+
+func NewRecording(index int, r *Region) *Recording {
+	return &Recording{
+		Index:  index,
+		Region: r,
+	}
+}
+
+func (rec *Recording) Placed(a *Attempt, sp *ShapePlacement) {
+	rec.add(Event{Kind: EventPlace, Placement: sp})
+}
+
+func (rec *Recording) Removed(a *Attempt, sp *ShapePlacement) {
+	rec.backtracks++
+	rec.add(Event{Kind: EventRemove, Placement: sp})
+}
+
+func (rec *Recording) add(e Event) {
+	if len(rec.Events) >= maxRecordedEvents {
+		rec.Truncated = true
+		return
+	}
+	e.Backtracks = rec.backtracks
+	rec.Events = append(rec.Events, e)
+}
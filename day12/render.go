@@ -0,0 +1,163 @@
+package day12
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/gdamore/tcell/v3"
+)
+
+var (
+	defStyle    = tcell.StyleDefault.Background(tcell.ColorReset).Foreground(tcell.ColorReset)
+	shapeStyles = []tcell.Style{
+		defStyle.Background(tcell.ColorBlue).Foreground(tcell.ColorWhite),
+		defStyle.Background(tcell.ColorRed).Foreground(tcell.ColorWhite),
+		defStyle.Background(tcell.ColorGreen).Foreground(tcell.ColorWhite),
+		defStyle.Background(tcell.ColorViolet).Foreground(tcell.ColorWhite),
+		defStyle.Background(tcell.ColorYellow).Foreground(tcell.ColorBlack),
+		defStyle.Background(tcell.ColorWhite).Foreground(tcell.ColorBlack),
+	}
+)
+
+// Renderer shows the packings that the solvers come up with.
+type Renderer interface {
+	// Render shows the attempt along with a status line. It returns false if the user asked to stop.
+	Render(a *Attempt, status string) bool
+	// Interrupted is closed when the user asks to stop. It may be nil if that cannot happen.
+	Interrupted() <-chan struct{}
+	// Close releases the resources of the renderer.
+	Close()
+}
+
+// NoopRenderer discards everything, so that Round1 can run without a terminal.
+type NoopRenderer struct{}
+
+func (NoopRenderer) Render(*Attempt, string) bool { return true }
+func (NoopRenderer) Interrupted() <-chan struct{} { return nil }
+func (NoopRenderer) Close()                       {}
+
+// TextRenderer prints the attempts as plain text.
+type TextRenderer struct {
+	w io.Writer
+}
+
+func NewTextRenderer(w io.Writer) *TextRenderer {
+	return &TextRenderer{w: w}
+}
+
+func (t *TextRenderer) Render(a *Attempt, status string) bool {
+	fmt.Fprintf(t.w, "%s\n%s% d\n\n", status, a, a.shapeCounts)
+	return true
+}
+
+func (t *TextRenderer) Interrupted() <-chan struct{} { return nil }
+func (t *TextRenderer) Close()                       {}
+
+// ScreenRenderer draws the attempts on the terminal with tcell. Pressing Esc or Ctrl+C interrupts, all other keys are
+// passed on to Keys.
+type ScreenRenderer struct {
+	screen tcell.Screen
+	stop   chan struct{}
+	keys   chan *tcell.EventKey
+}
+
+func NewScreenRenderer() (*ScreenRenderer, error) {
+	screen, err := tcell.NewScreen()
+	if err != nil {
+		return nil, err
+	}
+	if err := screen.Init(); err != nil {
+		return nil, err
+	}
+	s := &ScreenRenderer{
+		screen: screen,
+		stop:   make(chan struct{}),
+		keys:   make(chan *tcell.EventKey, 16),
+	}
+	go func() {
+		for {
+			ev := <-screen.EventQ()
+			switch ev := ev.(type) {
+			case *tcell.EventKey:
+				if ev.Key() == tcell.KeyEscape || ev.Key() == tcell.KeyCtrlC {
+					close(s.stop)
+					return
+				}
+				// Nobody might be listening, e.g., while the solvers are running.
+				select {
+				case s.keys <- ev:
+				default:
+				}
+			}
+		}
+	}()
+	return s, nil
+}
+
+func (s *ScreenRenderer) Render(a *Attempt, status string) bool {
+	s.draw(a, status)
+	s.screen.Show()
+	select {
+	case <-s.stop:
+		return false
+	default:
+		return true
+	}
+}
+
+// draw draws the attempt with the remaining shape counts and the status below it. It returns the first free line.
+func (s *ScreenRenderer) draw(a *Attempt, status string) int {
+	s.screen.Clear()
+	r := a.region
+	drawCanvas(s.screen, r.width, r.height, defStyle)
+	for i, sp := range a.placedShapes {
+		drawShape(s.screen, sp.x, sp.y, sp.shape, shapeMarkers[i%len(shapeMarkers)], shapeStyles[i%len(shapeStyles)])
+	}
+	drawText(s.screen, 0, r.height+3, 80, fmt.Sprintf("% d", a.shapeCounts), defStyle)
+	drawText(s.screen, 0, r.height+4, 80, status, defStyle)
+	return r.height + 5 + len(status)/80
+}
+
+// Keys delivers the key presses other than Esc and Ctrl+C.
+func (s *ScreenRenderer) Keys() <-chan *tcell.EventKey {
+	return s.keys
+}
+
+func (s *ScreenRenderer) Interrupted() <-chan struct{} {
+	return s.stop
+}
+
+func (s *ScreenRenderer) Close() {
+	s.screen.Fini()
+}
+
+func drawCanvas(screen tcell.Screen, width, height int, style tcell.Style) {
+	screen.Put(0, 0, string(tcell.RuneULCorner), style)
+	screen.Put(width+1, 0, string(tcell.RuneURCorner), style)
+	screen.Put(0, height+1, string(tcell.RuneLLCorner), style)
+	screen.Put(width+1, height+1, string(tcell.RuneLRCorner), style)
+	for x := 1; x <= width; x++ {
+		screen.Put(x, 0, string(tcell.RuneHLine), style)
+		screen.Put(x, height+1, string(tcell.RuneHLine), style)
+	}
+	for y := 1; y <= height; y++ {
+		screen.Put(0, y, string(tcell.RuneVLine), style)
+		screen.Put(width+1, y, string(tcell.RuneVLine), style)
+	}
+}
+
+func drawShape(screen tcell.Screen, ox, oy int, shape *Shape, r rune, style tcell.Style) {
+	for y, row := range shape.mask {
+		for x, set := range row {
+			if set {
+				screen.Put(1+ox+x, 1+oy+y, string(r), style)
+			}
+		}
+	}
+}
+
+func drawText(screen tcell.Screen, ox, oy, width int, text string, style tcell.Style) {
+	for i, r := range text {
+		screen.Put(ox+i%width, oy+i/width, string(r), style)
+	}
+}
@@ -0,0 +1,146 @@
+package day12
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/sekruse/adventofcode2025/sat"
+)
+
+// EncodeSAT encodes the packing of the region as CNF. There is one variable per placement of a shape variant. Each
+// cell is covered by at most one placement, and each shape is placed exactly as often as required. It returns the
+// formula and the placement of each variable.
+//
+// The copies of a shape share the placement variables, so swapping two copies gives the same assignment, and the
+// solver does not need to refute the permutations of an infeasible packing. Two more constraints help it refute a
+// region: each cell has a variable that tells if the cell is covered, and at most as many cells stay empty as the
+// area of the region exceeds the area of the shapes. Counting the empty cells column by column lets the counter see
+// the spare cells run out early. And the packings come in mirror images, so one copy of the rarest shape is required
+// to sit in the left and in the top half of the region, with its center on or before the middle.
+func EncodeSAT(r *Region, shapes []*Shape) (*sat.Formula, map[sat.Lit]*ShapePlacement) {
+	var f sat.Formula
+	placements := make(map[sat.Lit]*ShapePlacement)
+	cells := make([][]sat.Lit, r.width*r.height)
+	var left, top []sat.Lit
+	rarest := 0
+	for i, s := range shapes {
+		var count int
+		if i < len(r.shapeCounts) {
+			count = r.shapeCounts[i]
+		}
+		var lits, l2, t2 []sat.Lit
+		if count > 0 {
+			for _, v := range s.Variants() {
+				for y := 0; y+v.height <= r.height; y++ {
+					for x := 0; x+v.width <= r.width; x++ {
+						l := f.NewVar()
+						lits = append(lits, l)
+						placements[l] = &ShapePlacement{shape: v, x: x, y: y}
+						for sy, row := range v.mask {
+							for sx, isSet := range row {
+								if isSet {
+									cells[(y+sy)*r.width+x+sx] = append(cells[(y+sy)*r.width+x+sx], l)
+								}
+							}
+						}
+						// Twice the center, so that it stays an integer.
+						if 2*x+v.width <= r.width {
+							l2 = append(l2, l)
+						}
+						if 2*y+v.height <= r.height {
+							t2 = append(t2, l)
+						}
+					}
+				}
+			}
+			if rarest == 0 || count < rarest {
+				rarest, left, top = count, l2, t2
+			}
+		}
+		f.Exactly(lits, count)
+	}
+	covered := make([]sat.Lit, len(cells))
+	for c, lits := range cells {
+		f.AtMostOne(lits)
+		covered[c] = f.NewVar()
+		f.AddClause(append([]sat.Lit{covered[c].Not()}, lits...)...)
+		for _, l := range lits {
+			f.AddClause(l.Not(), covered[c])
+		}
+	}
+	var empty []sat.Lit
+	for x := 0; x < r.width; x++ {
+		for y := 0; y < r.height; y++ {
+			empty = append(empty, covered[y*r.width+x].Not())
+		}
+	}
+	f.AtMostK(empty, r.width*r.height-r.ShapeArea(shapes))
+	if rarest > 0 {
+		f.AddClause(left...)
+		f.AddClause(top...)
+	}
+	return &f, placements
+}
+
+// solveSAT packs a region with the CDCL solver from package sat.
+func solveSAT(r *Region, shapes []*Shape, obs Observer, stop <-chan struct{}) (*Attempt, error) {
+	// The cardinality constraints are expensive to refute, so we check the area ahead of time.
+	if r.ShapeArea(shapes) > r.width*r.height {
+		return nil, nil
+	}
+	f, placements := EncodeSAT(r, shapes)
+	solver := sat.NewSolver(f)
+	// The attempt follows the placements that the solver assigns and takes back. Until propagation finds the conflict,
+	// a placement may clash with an earlier one, so the stack keeps which placements the attempt holds.
+	attempt := NewAttempt(r)
+	attempt.Observe(obs)
+	var placed []bool
+	solver.SetTrace(func(l sat.Lit, assigned bool) {
+		sp, ok := placements[l]
+		if !ok {
+			return
+		}
+		if assigned {
+			placed = append(placed, attempt.Place(sp.shape, sp.x, sp.y) != nil)
+			return
+		}
+		if placed[len(placed)-1] {
+			attempt.Remove()
+		}
+		placed = placed[:len(placed)-1]
+	})
+	switch solver.Solve(stop) {
+	case sat.Unknown:
+		return nil, errInterrupted
+	case sat.Unsatisfiable:
+		return nil, nil
+	}
+	if len(attempt.placedShapes) != len(placed) {
+		return nil, fmt.Errorf("sat model places %d shapes illegally", len(placed)-len(attempt.placedShapes))
+	}
+	return attempt, nil
+}
+
+// writeDIMACS writes the SAT encoding of each region to a file of its own in the directory.
+func writeDIMACS(dir string, shapes []*Shape, regions []*Region) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for i, r := range regions {
+		f, _ := EncodeSAT(r, shapes)
+		file, err := os.Create(filepath.Join(dir, fmt.Sprintf("region%04d.cnf", i)))
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(file, "c Day 12 region %d: %dx%d with shape counts %v.\n", i, r.width, r.height, r.shapeCounts)
+		if err := f.WriteDIMACS(file); err != nil {
+			file.Close()
+			return err
+		}
+		if err := file.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,293 @@
+package day12
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+)
+
+var solutionHeaderRE = regexp.MustCompile(`^Region (\d+): (\d+)x(\d+)$`)
+
+// Layout draws the field with a letter per placed shape and dots for empty cells. Shapes whose tiles touch, even
+// diagonally, get different letters, so that each shape can be told apart when reading the layout back.
+func (a *Attempt) Layout() ([]string, error) {
+	var err error
+	rows := a.layout(func(i int, taken []bool) rune {
+		for j, m := range shapeMarkers {
+			if !taken[j] {
+				return m
+			}
+		}
+		err = fmt.Errorf("more than %d letters needed to tell the shapes apart", len(shapeMarkers))
+		return '?'
+	})
+	return rows, err
+}
+
+// layout draws the field with the letters picked for each placement. Besides the placement index, pick learns which
+// letters the neighbours of the placement that were placed before it have.
+func (a *Attempt) layout(pick func(i int, taken []bool) rune) []string {
+	r := a.region
+	owners := make([][]int, r.height)
+	grid := make([][]rune, r.height)
+	for y := range grid {
+		owners[y] = make([]int, r.width)
+		grid[y] = make([]rune, r.width)
+		for x := range grid[y] {
+			owners[y][x] = -1
+			grid[y][x] = '.'
+		}
+	}
+	for i, sp := range a.placedShapes {
+		sp.forEachTile(func(x, y int) {
+			owners[y][x] = i
+		})
+	}
+	letters := make([]rune, len(a.placedShapes))
+	for i, sp := range a.placedShapes {
+		taken := make([]bool, len(shapeMarkers))
+		sp.forEachTile(func(x, y int) {
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					nx, ny := x+dx, y+dy
+					if nx < 0 || ny < 0 || nx >= r.width || ny >= r.height {
+						continue
+					}
+					if j := owners[ny][nx]; j >= 0 && j < i {
+						if k := slices.Index(shapeMarkers, letters[j]); k >= 0 {
+							taken[k] = true
+						}
+					}
+				}
+			}
+		})
+		letters[i] = pick(i, taken)
+		sp.forEachTile(func(x, y int) {
+			grid[y][x] = letters[i]
+		})
+	}
+	rows := make([]string, r.height)
+	for y, row := range grid {
+		rows[y] = string(row)
+	}
+	return rows
+}
+
+// forEachTile calls f with the field coordinates of each tile of the placed shape.
+func (sp *ShapePlacement) forEachTile(f func(x, y int)) {
+	for sy, row := range sp.shape.mask {
+		for sx, isSet := range row {
+			if isSet {
+				f(sp.x+sx, sp.y+sy)
+			}
+		}
+	}
+}
+
+// writeSolution appends the layout of a packed region to a solutions file.
+func writeSolution(w io.Writer, index int, a *Attempt) error {
+	rows, err := a.Layout()
+	if err != nil {
+		return fmt.Errorf("region %d: %w", index, err)
+	}
+	if _, err := fmt.Fprintf(w, "Region %d: %dx%d\n", index, a.region.width, a.region.height); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if _, err := fmt.Fprintln(w, row); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintln(w)
+	return err
+}
+
+// Validate checks the layouts in a solutions file against the regions of the input: each letter must form variants of
+// the input shapes, all tiles must be within the region, and each region must contain exactly the required shapes.
+// It returns the number of valid layouts and an error listing all violations.
+func Validate(inputPath, solutionsPath string) (int, error) {
+	shapes, regions, err := LoadData(inputPath)
+	if err != nil {
+		return 0, err
+	}
+	file, err := os.Open(solutionsPath)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	var errs []error
+	var valid int
+	seen := make(map[int]bool)
+	scanner := bufio.NewScanner(file)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+		m := solutionHeaderRE.FindStringSubmatch(line)
+		if m == nil {
+			errs = append(errs, fmt.Errorf("line %d: expected region header, got %q", lineNo, line))
+			continue
+		}
+		index, _ := strconv.Atoi(m[1])
+		width, _ := strconv.Atoi(m[2])
+		height, _ := strconv.Atoi(m[3])
+		headerLine := lineNo
+		var rows []string
+		for len(rows) < height && scanner.Scan() {
+			lineNo++
+			rows = append(rows, scanner.Text())
+		}
+		if index >= len(regions) {
+			errs = append(errs, fmt.Errorf("line %d: there is no region %d", headerLine, index))
+			continue
+		}
+		if seen[index] {
+			errs = append(errs, fmt.Errorf("line %d: region %d appears twice", headerLine, index))
+			continue
+		}
+		seen[index] = true
+		r := regions[index]
+		if width != r.width || height != r.height {
+			errs = append(errs, fmt.Errorf("line %d: region %d is %dx%d, got %dx%d", headerLine, index, r.width, r.height, width, height))
+			continue
+		}
+		if regionErrs := validateLayout(r, shapes, rows, headerLine+1); len(regionErrs) > 0 {
+			errs = append(errs, regionErrs...)
+			continue
+		}
+		valid++
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	return valid, errors.Join(errs...)
+}
+
+// validateLayout checks the rows of a single layout starting at the given line number.
+func validateLayout(r *Region, shapes []*Shape, rows []string, firstLine int) []error {
+	var errs []error
+	if len(rows) != r.height {
+		return []error{fmt.Errorf("line %d: expected %d rows, got %d", firstLine, r.height, len(rows))}
+	}
+	grid := make([][]rune, r.height)
+	for y, row := range rows {
+		grid[y] = []rune(row)
+		if len(grid[y]) != r.width {
+			errs = append(errs, fmt.Errorf("line %d: expected %d cells, got %d", firstLine+y, r.width, len(grid[y])))
+			continue
+		}
+		for x, c := range grid[y] {
+			if c != '.' && !slices.Contains(shapeMarkers, c) {
+				errs = append(errs, fmt.Errorf("line %d: unexpected %q at column %d", firstLine+y, c, x+1))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	// Each connected group of a letter (including diagonal neighbours) must be a variant of one of the shapes.
+	var variants []*Shape
+	for _, s := range shapes {
+		variants = append(variants, s.Variants()...)
+	}
+	counts := make([]int, len(shapes))
+	visited := make([][]bool, r.height)
+	for y := range visited {
+		visited[y] = make([]bool, r.width)
+	}
+	for y := 0; y < r.height; y++ {
+		for x := 0; x < r.width; x++ {
+			if grid[y][x] == '.' || visited[y][x] {
+				continue
+			}
+			tiles := floodFill(grid, visited, x, y)
+			s := matchVariant(tiles, variants)
+			if s == nil {
+				errs = append(errs, fmt.Errorf("line %d: %q at column %d does not form any shape variant", firstLine+y, grid[y][x], x+1))
+				continue
+			}
+			counts[s.index]++
+		}
+	}
+	for i, want := range r.shapeCounts {
+		var got int
+		if i < len(counts) {
+			got = counts[i]
+		}
+		if got != want {
+			errs = append(errs, fmt.Errorf("line %d: expected %d of shape %d, got %d", firstLine-1, want, i, got))
+		}
+	}
+	return errs
+}
+
+// floodFill collects the cells with the same letter as (x, y) that are connected to it, including diagonally.
+func floodFill(grid [][]rune, visited [][]bool, x, y int) [][2]int {
+	c := grid[y][x]
+	var tiles [][2]int
+	queue := [][2]int{{x, y}}
+	visited[y][x] = true
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		tiles = append(tiles, p)
+		for dy := -1; dy <= 1; dy++ {
+			for dx := -1; dx <= 1; dx++ {
+				nx, ny := p[0]+dx, p[1]+dy
+				if ny < 0 || ny >= len(grid) || nx < 0 || nx >= len(grid[ny]) {
+					continue
+				}
+				if visited[ny][nx] || grid[ny][nx] != c {
+					continue
+				}
+				visited[ny][nx] = true
+				queue = append(queue, [2]int{nx, ny})
+			}
+		}
+	}
+	return tiles
+}
+
+// matchVariant returns the shape variant whose tiles are the given cells up to translation.
+func matchVariant(tiles [][2]int, variants []*Shape) *Shape {
+	normalize := func(tiles [][2]int) [][2]int {
+		minX, minY := tiles[0][0], tiles[0][1]
+		for _, t := range tiles {
+			minX, minY = min(minX, t[0]), min(minY, t[1])
+		}
+		res := make([][2]int, len(tiles))
+		for i, t := range tiles {
+			res[i] = [2]int{t[0] - minX, t[1] - minY}
+		}
+		slices.SortFunc(res, func(a, b [2]int) int {
+			if a[1] != b[1] {
+				return a[1] - b[1]
+			}
+			return a[0] - b[0]
+		})
+		return res
+	}
+	got := normalize(tiles)
+	for _, v := range variants {
+		var want [][2]int
+		for y, row := range v.mask {
+			for x, isSet := range row {
+				if isSet {
+					want = append(want, [2]int{x, y})
+				}
+			}
+		}
+		if len(want) > 0 && slices.Equal(normalize(want), got) {
+			return v
+		}
+	}
+	return nil
+}
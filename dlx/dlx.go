@@ -0,0 +1,288 @@
+// Package dlx solves exact cover problems with Knuth's Dancing Links (Algorithm X).
+//
+// Primary columns have to be covered by exactly as many selected rows as their multiplicity demands (one by default).
+// Secondary columns are optional, i.e., they may be covered at most once.
+package dlx
+
+import (
+	"iter"
+	"slices"
+)
+
+// Matrix is a sparse 0/1 matrix whose columns are the constraints and whose rows are the options to choose from.
+type Matrix struct {
+	primary, secondary int
+	// Every node, including the root (index 0) and the column headers (1..columns), is linked horizontally and
+	// vertically. Column headers are linked horizontally into the list of uncovered primary columns.
+	left, right, up, down []int
+	col, row              []int
+	size, need            []int
+	rows                  int
+	// interrupted is set when stop cuts the enumeration short.
+	interrupted bool
+	trace       func(row int, selected bool)
+}
+
+// New creates an empty matrix with the given numbers of primary and secondary columns. Columns are numbered from 0 and
+// the secondary columns come after the primary columns.
+func New(primary, secondary int) *Matrix {
+	m := &Matrix{
+		primary:   primary,
+		secondary: secondary,
+	}
+	n := 1 + primary + secondary
+	m.left = make([]int, n)
+	m.right = make([]int, n)
+	m.up = make([]int, n)
+	m.down = make([]int, n)
+	m.col = make([]int, n)
+	m.row = make([]int, n)
+	m.size = make([]int, n)
+	m.need = make([]int, n)
+	for i := 0; i < n; i++ {
+		m.up[i], m.down[i], m.col[i], m.row[i] = i, i, i, -1
+		m.left[i], m.right[i] = i, i
+	}
+	// Only primary columns are part of the header list.
+	for i := 1; i <= primary; i++ {
+		m.left[i] = i - 1
+		m.right[i-1] = i
+		m.need[i] = 1
+	}
+	m.left[0] = primary
+	m.right[primary] = 0
+	return m
+}
+
+// SetMultiplicity sets how many selected rows have to cover the given primary column. A multiplicity of 0 forbids all
+// rows covering the column.
+func (m *Matrix) SetMultiplicity(col, n int) {
+	if col < 0 || col >= m.primary {
+		panic("dlx: multiplicity of a secondary column")
+	}
+	m.need[col+1] = n
+}
+
+// SetTrace sets a function that is called whenever the search selects a row and when it takes the row back, in
+// reverse order of selection. It may be nil.
+func (m *Matrix) SetTrace(trace func(row int, selected bool)) {
+	m.trace = trace
+}
+
+// AddRow adds a row covering the given columns and returns its index.
+func (m *Matrix) AddRow(cols ...int) int {
+	r := m.rows
+	m.rows++
+	first := -1
+	for _, c := range cols {
+		if c < 0 || c >= m.primary+m.secondary {
+			panic("dlx: column out of range")
+		}
+		h := c + 1
+		i := len(m.col)
+		m.col = append(m.col, h)
+		m.row = append(m.row, r)
+		m.size = append(m.size, 0)
+		m.need = append(m.need, 0)
+		// Insert at the bottom of the column.
+		m.up = append(m.up, m.up[h])
+		m.down = append(m.down, h)
+		m.down[m.up[h]] = i
+		m.up[h] = i
+		m.size[h]++
+		// Insert at the end of the row.
+		if first < 0 {
+			first = i
+			m.left = append(m.left, i)
+			m.right = append(m.right, i)
+			continue
+		}
+		m.left = append(m.left, m.left[first])
+		m.right = append(m.right, first)
+		m.right[m.left[first]] = i
+		m.left[first] = i
+	}
+	return r
+}
+
+// Rows returns the number of rows in the matrix.
+func (m *Matrix) Rows() int {
+	return m.rows
+}
+
+// Solutions enumerates all solutions as the sorted indexes of the selected rows. Solutions are generated lazily, so
+// the caller can stop after the first one. The enumeration also ends early once stop is closed, which Interrupted
+// reports afterwards; stop may be nil.
+func (m *Matrix) Solutions(stop <-chan struct{}) iter.Seq[[]int] {
+	return func(yield func([]int) bool) {
+		m.interrupted = false
+		// Columns that must not be covered at all rule out all of their rows up front.
+		var forbidden []int
+		for c := 1; c <= m.primary; c++ {
+			if m.need[c] == 0 {
+				m.cover(c)
+				forbidden = append(forbidden, c)
+			}
+		}
+		var selected []int
+		m.search(&selected, stop, yield)
+		for i := len(forbidden) - 1; i >= 0; i-- {
+			m.uncover(forbidden[i])
+		}
+	}
+}
+
+// Interrupted reports whether the last enumeration of Solutions ended because stop was closed, rather than because
+// the search space was exhausted or the caller stopped.
+func (m *Matrix) Interrupted() bool {
+	return m.interrupted
+}
+
+func (m *Matrix) search(selected *[]int, stop <-chan struct{}, yield func([]int) bool) bool {
+	select {
+	case <-stop:
+		m.interrupted = true
+		return false
+	default:
+	}
+	if m.right[0] == 0 {
+		res := make([]int, len(*selected))
+		for i, node := range *selected {
+			res[i] = m.row[node]
+		}
+		slices.Sort(res)
+		return yield(res)
+	}
+	// Pick the column with the fewest rows left, or bail out if one cannot be satisfied anymore.
+	c := 0
+	for h := m.right[0]; h != 0; h = m.right[h] {
+		if m.size[h] < m.need[h] {
+			return true
+		}
+		if c == 0 || m.size[h]-m.need[h] < m.size[c]-m.need[c] {
+			c = h
+		}
+	}
+	// Each row is tried in turn. Once a row has been tried, it is hidden from the remaining branches, so that
+	// solutions selecting several rows of a column with multiplicity are not enumerated once per permutation.
+	var tried []int
+	ok := true
+	for i := m.down[c]; i != c; i = m.down[c] {
+		m.selectRow(i)
+		*selected = append(*selected, i)
+		if m.trace != nil {
+			m.trace(m.row[i], true)
+		}
+		ok = m.search(selected, stop, yield)
+		if m.trace != nil {
+			m.trace(m.row[i], false)
+		}
+		*selected = (*selected)[:len(*selected)-1]
+		m.deselectRow(i)
+		if !ok {
+			break
+		}
+		m.hideRow(i)
+		tried = append(tried, i)
+	}
+	for j := len(tried) - 1; j >= 0; j-- {
+		m.unhideRow(tried[j])
+	}
+	return ok
+}
+
+func (m *Matrix) selectRow(i int) {
+	m.hideRow(i)
+	j := i
+	for {
+		c := m.col[j]
+		if c > m.primary {
+			m.cover(c)
+		} else {
+			m.need[c]--
+			if m.need[c] == 0 {
+				m.cover(c)
+			}
+		}
+		j = m.right[j]
+		if j == i {
+			break
+		}
+	}
+}
+
+func (m *Matrix) deselectRow(i int) {
+	j := m.left[i]
+	for {
+		c := m.col[j]
+		if c > m.primary {
+			m.uncover(c)
+		} else {
+			if m.need[c] == 0 {
+				m.uncover(c)
+			}
+			m.need[c]++
+		}
+		if j == i {
+			break
+		}
+		j = m.left[j]
+	}
+	m.unhideRow(i)
+}
+
+// cover removes the column from the header list and all of its rows from the other columns.
+func (m *Matrix) cover(c int) {
+	if c <= m.primary {
+		m.right[m.left[c]] = m.right[c]
+		m.left[m.right[c]] = m.left[c]
+	}
+	for i := m.down[c]; i != c; i = m.down[i] {
+		for j := m.right[i]; j != i; j = m.right[j] {
+			m.up[m.down[j]] = m.up[j]
+			m.down[m.up[j]] = m.down[j]
+			m.size[m.col[j]]--
+		}
+	}
+}
+
+func (m *Matrix) uncover(c int) {
+	for i := m.up[c]; i != c; i = m.up[i] {
+		for j := m.left[i]; j != i; j = m.left[j] {
+			m.size[m.col[j]]++
+			m.up[m.down[j]] = j
+			m.down[m.up[j]] = j
+		}
+	}
+	if c <= m.primary {
+		m.right[m.left[c]] = c
+		m.left[m.right[c]] = c
+	}
+}
+
+// hideRow removes all nodes of the row from their columns.
+func (m *Matrix) hideRow(i int) {
+	j := i
+	for {
+		m.up[m.down[j]] = m.up[j]
+		m.down[m.up[j]] = m.down[j]
+		m.size[m.col[j]]--
+		j = m.right[j]
+		if j == i {
+			break
+		}
+	}
+}
+
+func (m *Matrix) unhideRow(i int) {
+	j := m.left[i]
+	for {
+		m.size[m.col[j]]++
+		m.up[m.down[j]] = j
+		m.down[m.up[j]] = j
+		if j == i {
+			break
+		}
+		j = m.left[j]
+	}
+}
@@ -0,0 +1,121 @@
+package dlx
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSolutions(t *testing.T) {
+	t.Run("Exact cover", func(t *testing.T) {
+		// The example from Knuth's paper on Dancing Links.
+		m := New(7, 0)
+		m.AddRow(2, 4, 5)
+		m.AddRow(0, 3, 6)
+		m.AddRow(1, 2, 5)
+		m.AddRow(0, 3)
+		m.AddRow(1, 6)
+		m.AddRow(3, 4, 6)
+		got := slices.Collect(m.Solutions(nil))
+		want := [][]int{{0, 3, 4}}
+		if !slices.EqualFunc(got, want, slices.Equal) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+	t.Run("Secondary columns", func(t *testing.T) {
+		// Two primary columns, one secondary column that both of the first rows claim.
+		m := New(2, 1)
+		m.AddRow(0, 2)
+		m.AddRow(1, 2)
+		m.AddRow(0)
+		m.AddRow(1)
+		got := slices.Collect(m.Solutions(nil))
+		want := [][]int{{0, 3}, {1, 2}, {2, 3}}
+		slices.SortFunc(got, slices.Compare)
+		if !slices.EqualFunc(got, want, slices.Equal) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+	t.Run("Multiplicity", func(t *testing.T) {
+		// Choose 2 out of 4 rows, each pair appears exactly once.
+		m := New(1, 4)
+		m.SetMultiplicity(0, 2)
+		for i := 0; i < 4; i++ {
+			m.AddRow(0, 1+i)
+		}
+		got := slices.Collect(m.Solutions(nil))
+		const want = 6
+		if len(got) != want {
+			t.Errorf("got %d solutions %v, want %d", len(got), got, want)
+		}
+	})
+	t.Run("Zero multiplicity", func(t *testing.T) {
+		m := New(2, 0)
+		m.SetMultiplicity(1, 0)
+		m.AddRow(0, 1)
+		m.AddRow(0)
+		got := slices.Collect(m.Solutions(nil))
+		want := [][]int{{1}}
+		if !slices.EqualFunc(got, want, slices.Equal) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+	t.Run("Early stop", func(t *testing.T) {
+		m := New(1, 3)
+		for i := 0; i < 3; i++ {
+			m.AddRow(0, 1+i)
+		}
+		var got int
+		for range m.Solutions(nil) {
+			got++
+			break
+		}
+		if got != 1 {
+			t.Errorf("got %d solutions, want 1", got)
+		}
+		// The matrix must be intact after the aborted enumeration.
+		if n := len(slices.Collect(m.Solutions(nil))); n != 3 {
+			t.Errorf("got %d solutions after restart, want 3", n)
+		}
+		if m.Interrupted() {
+			t.Error("got an interrupted enumeration, want a complete one")
+		}
+	})
+	t.Run("Trace", func(t *testing.T) {
+		m := New(2, 1)
+		m.AddRow(0, 2)
+		m.AddRow(1, 2)
+		m.AddRow(1)
+		var selected []int
+		m.SetTrace(func(row int, sel bool) {
+			if sel {
+				selected = append(selected, row)
+			} else if n := len(selected) - 1; n >= 0 && selected[n] == row {
+				selected = selected[:n]
+			} else {
+				t.Errorf("got row %d taken back, want the last of %v", row, selected)
+			}
+		})
+		for rows := range m.Solutions(nil) {
+			// At a solution, the trace holds its rows.
+			if got := slices.Sorted(slices.Values(selected)); !slices.Equal(got, rows) {
+				t.Errorf("got traced rows %v, want %v", got, rows)
+			}
+		}
+		if len(selected) != 0 {
+			t.Errorf("got rows %v left after the search, want none", selected)
+		}
+	})
+	t.Run("Interrupted", func(t *testing.T) {
+		m := New(2, 0)
+		m.AddRow(0)
+		stop := make(chan struct{})
+		// A search that is complete before stop is closed was not interrupted.
+		if n := len(slices.Collect(m.Solutions(stop))); n != 0 || m.Interrupted() {
+			t.Errorf("got %d solutions, interrupted %v, want 0, false", n, m.Interrupted())
+		}
+		close(stop)
+		if n := len(slices.Collect(m.Solutions(stop))); n != 0 || !m.Interrupted() {
+			t.Errorf("got %d solutions, interrupted %v, want 0, true", n, m.Interrupted())
+		}
+	})
+}
@@ -0,0 +1,442 @@
+// Package polygon analyses rectilinear polygons with integer vertices, i.e., polygons whose edges are all horizontal or
+// vertical. Y grows downwards, as on screen.
+package polygon
+
+import (
+	"fmt"
+	"slices"
+)
+
+type Point struct {
+	X, Y int
+}
+
+func (p Point) String() string {
+	return fmt.Sprintf("(%d, %d)", p.X, p.Y)
+}
+
+// Edge is a horizontal or vertical segment.
+type Edge struct {
+	From, To Point
+}
+
+func (e Edge) Vertical() bool {
+	return e.From.X == e.To.X
+}
+
+// Direction returns the unit vector from From to To.
+func (e Edge) Direction() Point {
+	return Point{X: sign(e.To.X - e.From.X), Y: sign(e.To.Y - e.From.Y)}
+}
+
+// Outward returns the unit normal of the edge that points outside, for a polygon with the given orientation.
+func (e Edge) Outward(orientation int) Point {
+	d := e.Direction()
+	return Point{X: d.Y * orientation, Y: -d.X * orientation}
+}
+
+// span returns the coordinate of the line that the edge lies on and the range that it covers on that line.
+func (e Edge) span() (line, lo, hi int) {
+	if e.Vertical() {
+		return e.From.X, min(e.From.Y, e.To.Y), max(e.From.Y, e.To.Y)
+	}
+	return e.From.Y, min(e.From.X, e.To.X), max(e.From.X, e.To.X)
+}
+
+// Polygon is a closed rectilinear path. The path may touch itself, e.g., by running along an earlier edge in the
+// opposite direction. The inside of the polygon is the set of points with a non-zero winding number.
+type Polygon struct {
+	vertices []Point
+	edges    []Edge
+	// exposed are the parts of the edges that separate the inside from the outside. It leaves out the parts where the
+	// path runs along itself in the opposite direction, since there is inside on both sides.
+	exposed []Edge
+}
+
+// New creates a polygon from its vertices in order. Each vertex must share exactly one coordinate with the next one,
+// and the last vertex with the first one.
+func New(vertices []Point) (*Polygon, error) {
+	if len(vertices) < 4 {
+		return nil, fmt.Errorf("expecting at least 4 vertices, got %d", len(vertices))
+	}
+	p := &Polygon{vertices: vertices}
+	for i, v := range vertices {
+		w := vertices[(i+1)%len(vertices)]
+		if (v.X == w.X) == (v.Y == w.Y) {
+			return nil, fmt.Errorf("edge %d from %s to %s is neither horizontal nor vertical", i, v, w)
+		}
+		p.edges = append(p.edges, Edge{From: v, To: w})
+	}
+	for i, e := range p.edges {
+		line, lo, hi := e.span()
+		pieces := [][2]int{{lo, hi}}
+		for j, f := range p.edges {
+			if j == i || f.Vertical() != e.Vertical() || f.Direction() == e.Direction() {
+				continue
+			}
+			fLine, fLo, fHi := f.span()
+			if fLine != line {
+				continue
+			}
+			var rest [][2]int
+			for _, piece := range pieces {
+				if piece[0] < fLo {
+					rest = append(rest, [2]int{piece[0], min(piece[1], fLo)})
+				}
+				if piece[1] > fHi {
+					rest = append(rest, [2]int{max(piece[0], fHi), piece[1]})
+				}
+			}
+			pieces = rest
+		}
+		for _, piece := range pieces {
+			if e.Vertical() {
+				p.exposed = append(p.exposed, Edge{From: Point{line, piece[0]}, To: Point{line, piece[1]}})
+			} else {
+				p.exposed = append(p.exposed, Edge{From: Point{piece[0], line}, To: Point{piece[1], line}})
+			}
+		}
+	}
+	return p, nil
+}
+
+func (p *Polygon) Vertices() []Point {
+	return p.vertices
+}
+
+// Edges returns the edges in order, edge i going from vertex i to vertex i+1.
+func (p *Polygon) Edges() []Edge {
+	return p.edges
+}
+
+// SignedArea computes the area with the shoelace formula. It is positive if the vertices run clockwise on screen and
+// negative otherwise.
+func (p *Polygon) SignedArea() int {
+	var sum int
+	for _, e := range p.edges {
+		sum += e.From.X*e.To.Y - e.To.X*e.From.Y
+	}
+	return sum / 2
+}
+
+func (p *Polygon) Area() int {
+	return abs(p.SignedArea())
+}
+
+// Orientation is +1 if the vertices run clockwise on screen and -1 if they run counterclockwise.
+func (p *Polygon) Orientation() int {
+	return sign(p.SignedArea())
+}
+
+// ConcaveCorners returns the indexes of the vertices where the polygon turns against its orientation.
+func (p *Polygon) ConcaveCorners() []int {
+	var res []int
+	orientation := p.Orientation()
+	for i, e := range p.edges {
+		dPrev, d := p.edges[(i+len(p.edges)-1)%len(p.edges)].Direction(), e.Direction()
+		if dPrev.X*d.Y-d.X*dPrev.Y == -orientation {
+			res = append(res, i)
+		}
+	}
+	return res
+}
+
+// Winding returns the winding number of the point, i.e., how often the polygon runs around it clockwise. It is only
+// meaningful for points that are not on the boundary.
+func (p *Polygon) Winding(pt Point) int {
+	return p.winding2(2*pt.X, 2*pt.Y)
+}
+
+// winding2 computes the winding number for the point (x2/2, y2/2) by casting a ray to the right and counting the
+// vertical edges that it crosses, downward edges positively and upward edges negatively. Each edge covers its upper
+// end but not its lower end, so that the ray crosses a vertex only once.
+func (p *Polygon) winding2(x2, y2 int) int {
+	var res int
+	for _, e := range p.edges {
+		if !e.Vertical() || 2*e.From.X <= x2 {
+			continue
+		}
+		if y0, y1 := 2*e.From.Y, 2*e.To.Y; y0 < y1 && y0 <= y2 && y2 < y1 {
+			res++
+		} else if y1 < y0 && y1 <= y2 && y2 < y0 {
+			res--
+		}
+	}
+	return res
+}
+
+// OnBoundary checks if the point lies on an edge.
+func (p *Polygon) OnBoundary(pt Point) bool {
+	return p.onBoundary2(2*pt.X, 2*pt.Y)
+}
+
+func (p *Polygon) onBoundary2(x2, y2 int) bool {
+	for _, e := range p.edges {
+		line, lo, hi := e.span()
+		if e.Vertical() && 2*line == x2 && 2*lo <= y2 && y2 <= 2*hi {
+			return true
+		}
+		if !e.Vertical() && 2*line == y2 && 2*lo <= x2 && x2 <= 2*hi {
+			return true
+		}
+	}
+	return false
+}
+
+// Contains checks if the point is inside the polygon or on its boundary.
+func (p *Polygon) Contains(pt Point) bool {
+	return p.contains2(2*pt.X, 2*pt.Y)
+}
+
+func (p *Polygon) contains2(x2, y2 int) bool {
+	return p.onBoundary2(x2, y2) || p.winding2(x2, y2) != 0
+}
+
+// ContainsRect checks if the axis-parallel rectangle spanned by the two corners lies completely inside the polygon,
+// including its boundary.
+func (p *Polygon) ContainsRect(a, b Point) bool {
+	x1, x2 := min(a.X, b.X), max(a.X, b.X)
+	y1, y2 := min(a.Y, b.Y), max(a.Y, b.Y)
+	if x1 == x2 || y1 == y2 {
+		return p.containsSegment(Point{x1, y1}, Point{x2, y2})
+	}
+	// If no exposed edge cuts through the interior of the rectangle, then the interior lies either completely inside
+	// or completely outside.
+	for _, e := range p.exposed {
+		line, lo, hi := e.span()
+		if e.Vertical() && x1 < line && line < x2 && max(lo, y1) < min(hi, y2) {
+			return false
+		}
+		if !e.Vertical() && y1 < line && line < y2 && max(lo, x1) < min(hi, x2) {
+			return false
+		}
+	}
+	// No edge passes through the center of the top-left cell of the rectangle, so its winding number is well-defined.
+	return p.winding2(2*x1+1, 2*y1+1) != 0
+}
+
+// containsSegment checks if the horizontal or vertical segment from a to b, a <= b, lies inside the polygon. Between
+// the coordinates of the vertices, the segment is either inside or outside, so we check those and the points halfway
+// between them.
+func (p *Polygon) containsSegment(a, b Point) bool {
+	vertical := a.X == b.X
+	lo, hi := a.X, b.X
+	if vertical {
+		lo, hi = a.Y, b.Y
+	}
+	stops := []int{2 * lo, 2 * hi}
+	for _, v := range p.vertices {
+		c := v.X
+		if vertical {
+			c = v.Y
+		}
+		if lo < c && c < hi {
+			stops = append(stops, 2*c)
+		}
+	}
+	slices.Sort(stops)
+	for i, s := range stops {
+		if i > 0 {
+			if mid := (stops[i-1] + s) / 2; !p.containsAt2(vertical, 2*a.X, 2*a.Y, mid) {
+				return false
+			}
+		}
+		if !p.containsAt2(vertical, 2*a.X, 2*a.Y, s) {
+			return false
+		}
+	}
+	return true
+}
+
+// containsAt2 checks the point on the doubled segment through (x2, y2) at the doubled coordinate c along it.
+func (p *Polygon) containsAt2(vertical bool, x2, y2, c int) bool {
+	if vertical {
+		return p.contains2(x2, c)
+	}
+	return p.contains2(c, y2)
+}
+
+// Contact is a point where two edges meet, other than consecutive edges at their shared vertex.
+type Contact struct {
+	Edges [2]int
+	At    Point
+	// Crossing tells if the edges cross each other. Otherwise, they only touch.
+	Crossing bool
+}
+
+func (c Contact) String() string {
+	kind := "touch"
+	if c.Crossing {
+		kind = "cross"
+	}
+	return fmt.Sprintf("edges %d and %d %s at %s", c.Edges[0], c.Edges[1], kind, c.At)
+}
+
+// Contacts finds all places where the polygon touches or crosses itself. Consecutive edges only count if they run
+// back along each other.
+func (p *Polygon) Contacts() []Contact {
+	var res []Contact
+	n := len(p.edges)
+	for i, e := range p.edges {
+		for j := i + 1; j < n; j++ {
+			f := p.edges[j]
+			if j == i+1 || (i == 0 && j == n-1) {
+				// Consecutive edges share a vertex. They only meet elsewhere if the path turns back.
+				if e.Vertical() == f.Vertical() && e.Direction() != f.Direction() {
+					at := f.From
+					if j != i+1 {
+						at = e.From
+					}
+					res = append(res, Contact{Edges: [2]int{i, j}, At: at})
+				}
+				continue
+			}
+			if c, ok := intersect(e, f); ok {
+				c.Edges = [2]int{i, j}
+				res = append(res, c)
+			}
+		}
+	}
+	return res
+}
+
+// IsSimple checks that the polygon neither touches nor crosses itself.
+func (p *Polygon) IsSimple() bool {
+	return len(p.Contacts()) == 0
+}
+
+func intersect(e, f Edge) (Contact, bool) {
+	eLine, eLo, eHi := e.span()
+	fLine, fLo, fHi := f.span()
+	if e.Vertical() == f.Vertical() {
+		if eLine != fLine || max(eLo, fLo) > min(eHi, fHi) {
+			return Contact{}, false
+		}
+		at := Point{X: eLine, Y: max(eLo, fLo)}
+		if !e.Vertical() {
+			at = Point{X: max(eLo, fLo), Y: eLine}
+		}
+		return Contact{At: at}, true
+	}
+	// The line of each edge must lie in the range of the other one.
+	if fLine < eLo || fLine > eHi || eLine < fLo || eLine > fHi {
+		return Contact{}, false
+	}
+	at := Point{X: eLine, Y: fLine}
+	if !e.Vertical() {
+		at = Point{X: fLine, Y: eLine}
+	}
+	return Contact{
+		At:       at,
+		Crossing: eLo < fLine && fLine < eHi && fLo < eLine && eLine < fHi,
+	}, true
+}
+
+func abs(i int) int {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
+
+func sign(i int) int {
+	if i < 0 {
+		return -1
+	}
+	if i > 0 {
+		return 1
+	}
+	return 0
+}
+
+// Raster is the polygon on a grid of compressed coordinates. The grid has a row for each distinct Y of the vertices
+// and a row for the space between each two of them, and likewise columns. Each cell is either inside the polygon or
+// outside as a whole. A summed-area table over the cells outside answers whether rectangles are inside in constant time.
+type Raster struct {
+	xs, ys []int
+	// outside[r*(cols+1)+c] counts the cells outside in the rows before r and the columns before c.
+	outside    []int
+	rows, cols int
+}
+
+// Rasterize computes the raster of the polygon.
+func (p *Polygon) Rasterize() *Raster {
+	var xs, ys []int
+	for _, v := range p.vertices {
+		xs, ys = append(xs, v.X), append(ys, v.Y)
+	}
+	slices.Sort(xs)
+	slices.Sort(ys)
+	r := &Raster{xs: slices.Compact(xs), ys: slices.Compact(ys)}
+	r.rows, r.cols = 2*len(r.ys)-1, 2*len(r.xs)-1
+	// Same as winding2, a vertical edge adds to the winding number of the cells to its left, from the row of its upper
+	// end up to, but excluding, the row of its lower end.
+	winding := make([][]int, r.rows)
+	boundary := make([][]bool, r.rows)
+	for i := range winding {
+		winding[i] = make([]int, r.cols+1)
+		boundary[i] = make([]bool, r.cols)
+	}
+	for _, e := range p.edges {
+		c0, r0 := r.cell(e.From.X, r.xs), r.cell(e.From.Y, r.ys)
+		c1, r1 := r.cell(e.To.X, r.xs), r.cell(e.To.Y, r.ys)
+		for row := min(r0, r1); row <= max(r0, r1); row++ {
+			for col := min(c0, c1); col <= max(c0, c1); col++ {
+				boundary[row][col] = true
+			}
+		}
+		if e.Vertical() {
+			d := e.Direction().Y
+			for row := min(r0, r1); row < max(r0, r1); row++ {
+				winding[row][c0] += d
+			}
+		}
+	}
+	r.outside = make([]int, (r.rows+1)*(r.cols+1))
+	for row := 0; row < r.rows; row++ {
+		// Sum up the edges to the right of each cell.
+		w := 0
+		for col := r.cols - 1; col >= 0; col-- {
+			w += winding[row][col+1]
+			if !boundary[row][col] && w == 0 {
+				r.outside[(row+1)*(r.cols+1)+col+1] = 1
+			}
+		}
+		for col := 0; col < r.cols; col++ {
+			i := (row+1)*(r.cols+1) + col + 1
+			r.outside[i] += r.outside[i-1] + r.outside[i-r.cols-1] - r.outside[i-r.cols-2]
+		}
+	}
+	return r
+}
+
+// Size returns the number of rows and columns.
+func (r *Raster) Size() (rows, cols int) {
+	return r.rows, r.cols
+}
+
+// cell returns the row or column of the coordinate c in the compressed coordinates cs. That is 2i if c equals cs[i],
+// 2i+1 if it lies between cs[i] and cs[i+1], and -1 if it lies before or after all of them.
+func (r *Raster) cell(c int, cs []int) int {
+	i, found := slices.BinarySearch(cs, c)
+	switch {
+	case found:
+		return 2 * i
+	case i == 0 || i == len(cs):
+		return -1
+	}
+	return 2*i - 1
+}
+
+// ContainsRect checks if the axis-parallel rectangle spanned by the two corners lies completely inside the polygon,
+// including its boundary, just like Polygon.ContainsRect.
+func (r *Raster) ContainsRect(a, b Point) bool {
+	c0, c1 := r.cell(min(a.X, b.X), r.xs), r.cell(max(a.X, b.X), r.xs)
+	r0, r1 := r.cell(min(a.Y, b.Y), r.ys), r.cell(max(a.Y, b.Y), r.ys)
+	if c0 < 0 || c1 < 0 || r0 < 0 || r1 < 0 {
+		return false
+	}
+	w := r.cols + 1
+	outside := r.outside[(r1+1)*w+c1+1] - r.outside[r0*w+c1+1] - r.outside[(r1+1)*w+c0] + r.outside[r0*w+c0]
+	return outside == 0
+}
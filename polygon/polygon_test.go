@@ -0,0 +1,188 @@
+package polygon
+
+import (
+	"slices"
+	"testing"
+)
+
+var (
+	// An L with its vertices running clockwise.
+	lShape = []Point{{0, 0}, {2, 0}, {2, 3}, {5, 3}, {5, 5}, {0, 5}}
+	// A C whose lower arm has a bump that runs along the upper arm from (4, 2) to (5, 2). That cuts off a hole from
+	// (2, 2) to (4, 4), and the perimeter touches itself.
+	touching = []Point{{0, 0}, {6, 0}, {6, 2}, {2, 2}, {2, 4}, {4, 4}, {4, 2}, {5, 2}, {5, 4}, {6, 4}, {6, 6}, {0, 6}}
+	// A path that crosses itself at (2, 2).
+	crossing = []Point{{0, 2}, {4, 2}, {4, 4}, {2, 4}, {2, 0}, {0, 0}}
+)
+
+func mustNew(t *testing.T, vertices []Point) *Polygon {
+	t.Helper()
+	p, err := New(vertices)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return p
+}
+
+func TestArea(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		vertices []Point
+		want     int
+	}{
+		{"Square", []Point{{0, 0}, {3, 0}, {3, 3}, {0, 3}}, 9},
+		{"Counterclockwise square", []Point{{0, 0}, {0, 3}, {3, 3}, {3, 0}}, -9},
+		{"L", lShape, 16},
+		{"Touching", touching, 30},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := mustNew(t, tc.vertices).SignedArea(); got != tc.want {
+				t.Errorf("got %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestConcaveCorners(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		vertices []Point
+		want     []int
+	}{
+		{"L", lShape, []int{2}},
+		{"Counterclockwise L", []Point{{0, 5}, {5, 5}, {5, 3}, {2, 3}, {2, 0}, {0, 0}}, []int{3}},
+		{"Touching", touching, []int{3, 4, 5, 8}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := mustNew(t, tc.vertices).ConcaveCorners(); !slices.Equal(got, tc.want) {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestContains(t *testing.T) {
+	p := mustNew(t, lShape)
+	for _, tc := range []struct {
+		pt   Point
+		want bool
+	}{
+		{Point{1, 1}, true},
+		{Point{3, 4}, true},
+		{Point{0, 3}, true}, // on an edge
+		{Point{2, 3}, true}, // on a concave corner
+		{Point{3, 1}, false},
+		{Point{6, 3}, false}, // on the line of an edge
+		{Point{-1, 0}, false},
+	} {
+		if got := p.Contains(tc.pt); got != tc.want {
+			t.Errorf("%s: got %t, want %t", tc.pt, got, tc.want)
+		}
+	}
+	p = mustNew(t, touching)
+	for _, tc := range []struct {
+		pt   Point
+		want bool
+	}{
+		{Point{3, 3}, false}, // in the hole
+		{Point{4, 3}, true},  // on the bump
+		{Point{5, 3}, true},
+		{Point{6, 3}, false},
+		{Point{4, 2}, true}, // where the perimeter touches itself
+	} {
+		if got := p.Contains(tc.pt); got != tc.want {
+			t.Errorf("%s: got %t, want %t", tc.pt, got, tc.want)
+		}
+	}
+}
+
+func TestContainsRect(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		vertices []Point
+	}{
+		{"L", lShape},
+		{"Touching", touching},
+		{"Crossing", crossing},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			p := mustNew(t, tc.vertices)
+			raster := p.Rasterize()
+			// Compare against checking all points on a grid of half units, which does not miss any part of the
+			// polygon.
+			for x1 := -1; x1 <= 7; x1++ {
+				for y1 := -1; y1 <= 7; y1++ {
+					for x2 := x1; x2 <= 7; x2++ {
+						for y2 := y1; y2 <= 7; y2++ {
+							want := true
+							for x := 2 * x1; x <= 2*x2 && want; x++ {
+								for y := 2 * y1; y <= 2*y2 && want; y++ {
+									want = p.contains2(x, y)
+								}
+							}
+							if got := p.ContainsRect(Point{x1, y2}, Point{x2, y1}); got != want {
+								t.Errorf("rectangle from (%d, %d) to (%d, %d): got %t, want %t", x1, y1, x2, y2, got, want)
+							}
+							if got := raster.ContainsRect(Point{x1, y2}, Point{x2, y1}); got != want {
+								t.Errorf("rectangle from (%d, %d) to (%d, %d) in raster: got %t, want %t", x1, y1, x2, y2, got, want)
+							}
+						}
+					}
+				}
+			}
+		})
+	}
+	// The bump and the upper arm together form a rectangle across the touching edges.
+	p := mustNew(t, touching)
+	if !p.ContainsRect(Point{4, 0}, Point{5, 4}) {
+		t.Errorf("rectangle across the touching edges is not contained")
+	}
+}
+
+func TestContacts(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		vertices []Point
+		want     []Contact
+	}{
+		{"L", lShape, nil},
+		{"Touching", touching, []Contact{
+			{Edges: [2]int{2, 5}, At: Point{4, 2}},
+			{Edges: [2]int{2, 6}, At: Point{4, 2}},
+			{Edges: [2]int{2, 7}, At: Point{5, 2}},
+		}},
+		{"Crossing", crossing, []Contact{{Edges: [2]int{0, 3}, At: Point{2, 2}, Crossing: true}}},
+		{"Turning back", []Point{{0, 0}, {4, 0}, {2, 0}, {2, 2}, {0, 2}}, []Contact{
+			{Edges: [2]int{0, 1}, At: Point{4, 0}},
+			{Edges: [2]int{0, 2}, At: Point{2, 0}},
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			p := mustNew(t, tc.vertices)
+			got := p.Contacts()
+			if len(got) != len(tc.want) {
+				t.Fatalf("got %v, want %v", got, tc.want)
+			}
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Errorf("got %v, want %v", got[i], tc.want[i])
+				}
+			}
+			if got, want := p.IsSimple(), len(tc.want) == 0; got != want {
+				t.Errorf("IsSimple: got %t, want %t", got, want)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	for _, vertices := range [][]Point{
+		{{0, 0}, {1, 0}, {1, 1}},
+		{{0, 0}, {2, 0}, {1, 1}, {0, 1}},
+		{{0, 0}, {1, 0}, {1, 0}, {1, 1}, {0, 1}},
+	} {
+		if _, err := New(vertices); err == nil {
+			t.Errorf("%v: got no error", vertices)
+		}
+	}
+}
@@ -0,0 +1,265 @@
+// Package polyomino handles sets of grid cells under rotations and reflections, i.e., the dihedral group D4.
+package polyomino
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+// Cell is a grid position with X growing to the right and Y growing downwards.
+type Cell struct {
+	X, Y int
+}
+
+func compareCells(a, b Cell) int {
+	if a.Y != b.Y {
+		return cmp.Compare(a.Y, b.Y)
+	}
+	return cmp.Compare(a.X, b.X)
+}
+
+// Polyomino is a set of cells, translated to the top-left corner so that its smallest X and Y are 0. The cells need
+// not be connected, and they may enclose holes.
+type Polyomino struct {
+	cells         []Cell // sorted row by row
+	width, height int
+}
+
+// New creates a polyomino from the given cells. Duplicate cells are ignored.
+func New(cells []Cell) Polyomino {
+	var p Polyomino
+	if len(cells) == 0 {
+		return p
+	}
+	minX, minY := cells[0].X, cells[0].Y
+	for _, c := range cells {
+		minX, minY = min(minX, c.X), min(minY, c.Y)
+	}
+	for _, c := range cells {
+		c = Cell{c.X - minX, c.Y - minY}
+		p.cells = append(p.cells, c)
+		p.width, p.height = max(p.width, c.X+1), max(p.height, c.Y+1)
+	}
+	slices.SortFunc(p.cells, compareCells)
+	p.cells = slices.Compact(p.cells)
+	return p
+}
+
+// FromMask creates a polyomino from the set cells of the mask. The mask may contain empty rows and columns.
+func FromMask(mask [][]bool) Polyomino {
+	var cells []Cell
+	for y, row := range mask {
+		for x, isSet := range row {
+			if isSet {
+				cells = append(cells, Cell{x, y})
+			}
+		}
+	}
+	return New(cells)
+}
+
+// Parse creates a polyomino from rows of '#' (set) and '.' (unset) separated by newlines.
+func Parse(s string) Polyomino {
+	var cells []Cell
+	for y, line := range strings.Split(strings.TrimSpace(s), "\n") {
+		for x, r := range strings.TrimSpace(line) {
+			if r == '#' {
+				cells = append(cells, Cell{x, y})
+			}
+		}
+	}
+	return New(cells)
+}
+
+// Cells returns the cells row by row.
+func (p Polyomino) Cells() []Cell {
+	return slices.Clone(p.cells)
+}
+
+// Size returns the number of cells.
+func (p Polyomino) Size() int {
+	return len(p.cells)
+}
+
+// Bounds returns the width and height of the bounding box.
+func (p Polyomino) Bounds() (width, height int) {
+	return p.width, p.height
+}
+
+// Mask returns the polyomino as rows of its bounding box.
+func (p Polyomino) Mask() [][]bool {
+	mask := make([][]bool, p.height)
+	for y := range mask {
+		mask[y] = make([]bool, p.width)
+	}
+	for _, c := range p.cells {
+		mask[c.Y][c.X] = true
+	}
+	return mask
+}
+
+func (p Polyomino) String() string {
+	var sb strings.Builder
+	for _, row := range p.Mask() {
+		for _, isSet := range row {
+			if isSet {
+				sb.WriteByte('#')
+			} else {
+				sb.WriteByte('.')
+			}
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// Equal checks if both polyominoes have the same cells.
+func (p Polyomino) Equal(q Polyomino) bool {
+	return slices.Equal(p.cells, q.cells)
+}
+
+// Compare orders polyominoes lexicographically by their cells row by row.
+func (p Polyomino) Compare(q Polyomino) int {
+	return slices.CompareFunc(p.cells, q.cells, compareCells)
+}
+
+// Transform is an element of D4: Rotations by multiples of 90 degrees clockwise, optionally preceded by a flip
+// left-to-right.
+type Transform int
+
+const (
+	Identity Transform = iota
+	Rotate90
+	Rotate180
+	Rotate270
+	FlipLR
+	FlipLRRotate90
+	FlipLRRotate180
+	FlipLRRotate270
+)
+
+// Transforms lists all elements of D4, starting with the identity.
+var Transforms = []Transform{Identity, Rotate90, Rotate180, Rotate270, FlipLR, FlipLRRotate90, FlipLRRotate180, FlipLRRotate270}
+
+func (t Transform) apply(c Cell) Cell {
+	if t >= FlipLR {
+		c.X = -c.X
+	}
+	for i := 0; i < int(t)%4; i++ {
+		c = Cell{-c.Y, c.X}
+	}
+	return c
+}
+
+// Transform applies the transformation and moves the result back to the top-left corner.
+func (p Polyomino) Transform(t Transform) Polyomino {
+	cells := make([]Cell, len(p.cells))
+	for i, c := range p.cells {
+		cells[i] = t.apply(c)
+	}
+	return New(cells)
+}
+
+// Variants returns the distinct images of the polyomino under D4, starting with the polyomino itself.
+func (p Polyomino) Variants() []Polyomino {
+	var res []Polyomino
+	for _, t := range Transforms {
+		q := p.Transform(t)
+		if !slices.ContainsFunc(res, q.Equal) {
+			res = append(res, q)
+		}
+	}
+	return res
+}
+
+// Canonical returns the lexicographically smallest variant, which is the same for all variants.
+func (p Polyomino) Canonical() Polyomino {
+	return slices.MinFunc(p.Variants(), Polyomino.Compare)
+}
+
+// Symmetry classifies a polyomino by the subgroup of D4 that maps it onto itself.
+type Symmetry int
+
+const (
+	C1 Symmetry = iota // no symmetry
+	C2                 // rotation by 180 degrees
+	C4                 // rotation by 90 degrees
+	D1                 // a single mirror axis
+	D2                 // two mirror axes, hence also rotation by 180 degrees
+	D4                 // all symmetries of the square
+)
+
+func (s Symmetry) String() string {
+	return [...]string{"C1", "C2", "C4", "D1", "D2", "D4"}[s]
+}
+
+// Variants returns the number of distinct variants of a polyomino with this symmetry.
+func (s Symmetry) Variants() int {
+	return [...]int{8, 4, 2, 4, 2, 1}[s]
+}
+
+// Symmetry returns the symmetry group of the polyomino.
+func (p Polyomino) Symmetry() Symmetry {
+	var stabilizer []Transform
+	for _, t := range Transforms {
+		if p.Transform(t).Equal(p) {
+			stabilizer = append(stabilizer, t)
+		}
+	}
+	switch len(stabilizer) {
+	case 8:
+		return D4
+	case 4:
+		if slices.Contains(stabilizer, Rotate90) {
+			return C4
+		}
+		return D2
+	case 2:
+		if slices.Contains(stabilizer, Rotate180) {
+			return C2
+		}
+		return D1
+	}
+	return C1
+}
+
+// Holes counts the connected areas of unset cells that are enclosed by the polyomino, where cells are connected to
+// their four direct neighbours.
+func (p Polyomino) Holes() int {
+	// Pad the mask by one cell, so that the outside is connected around the polyomino.
+	w, h := p.width+2, p.height+2
+	set := make([]bool, w*h)
+	for _, c := range p.cells {
+		set[(c.Y+1)*w+c.X+1] = true
+	}
+	visited := make([]bool, w*h)
+	fill := func(start int) {
+		stack := []int{start}
+		visited[start] = true
+		for len(stack) > 0 {
+			i := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			x, y := i%w, i/w
+			for _, n := range [][2]int{{x - 1, y}, {x + 1, y}, {x, y - 1}, {x, y + 1}} {
+				if n[0] < 0 || n[1] < 0 || n[0] >= w || n[1] >= h {
+					continue
+				}
+				j := n[1]*w + n[0]
+				if !set[j] && !visited[j] {
+					visited[j] = true
+					stack = append(stack, j)
+				}
+			}
+		}
+	}
+	fill(0)
+	var holes int
+	for i := range set {
+		if !set[i] && !visited[i] {
+			holes++
+			fill(i)
+		}
+	}
+	return holes
+}
@@ -0,0 +1,77 @@
+package polyomino
+
+import (
+	"testing"
+)
+
+func TestSymmetry(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		shape string
+		want  Symmetry
+	}{
+		{"F pentomino", ".##\n##.\n.#.", C1},
+		{"S tetromino", ".##\n##.", C2},
+		{"Pinwheel", ".#..\n.###\n###.\n..#.", C4},
+		{"T tetromino", "###\n.#.", D1},
+		{"L tromino", "#.\n##", D1},
+		{"I tetromino", "####", D2},
+		{"Domino with a gap", "#.#", D2},
+		{"O tetromino", "##\n##", D4},
+		{"Ring", "###\n#.#\n###", D4},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			p := Parse(tc.shape)
+			if got := p.Symmetry(); got != tc.want {
+				t.Errorf("got %s, want %s", got, tc.want)
+			}
+			if got, want := len(p.Variants()), tc.want.Variants(); got != want {
+				t.Errorf("got %d variants, want %d", got, want)
+			}
+		})
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	p := Parse(".##\n##.\n.#.")
+	want := p.Canonical()
+	for _, v := range p.Variants() {
+		if got := v.Canonical(); !got.Equal(want) {
+			t.Errorf("canonical form of\n%sgot\n%swant\n%s", v, got, want)
+		}
+		if v.Compare(want) < 0 {
+			t.Errorf("variant\n%sis smaller than the canonical form\n%s", v, want)
+		}
+	}
+	// Masks with empty rows and columns are moved to the top-left corner.
+	masked := FromMask([][]bool{
+		{false, false, false, false},
+		{false, false, true, true},
+		{false, true, true, false},
+		{false, false, true, false},
+	})
+	if !masked.Equal(p) {
+		t.Errorf("got\n%swant\n%s", masked, p)
+	}
+}
+
+func TestHoles(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		shape string
+		want  int
+	}{
+		{"Plus", ".#.\n###\n.#.", 0},
+		{"Ring", "###\n#.#\n###", 1},
+		{"Two rings", "#####\n#.#.#\n#####", 2},
+		// The hole touches the outside diagonally only.
+		{"Open ring", "##.\n#.#\n###", 1},
+		{"Cup", "#.#\n###", 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Parse(tc.shape).Holes(); got != tc.want {
+				t.Errorf("got %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package sat
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ReadDIMACS parses a formula in DIMACS CNF format.
+func ReadDIMACS(r io.Reader) (*Formula, error) {
+	var f Formula
+	numClauses := -1
+	var clause []Lit
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "c") {
+			continue
+		}
+		// Some benchmark collections end their files with a lone "%".
+		if line == "%" {
+			break
+		}
+		if strings.HasPrefix(line, "p") {
+			fields := strings.Fields(line)
+			if len(fields) != 4 || fields[1] != "cnf" || numClauses >= 0 {
+				return nil, fmt.Errorf("line %d: unexpected problem line %q", lineNo, line)
+			}
+			var err error
+			f.NumVars, err = strconv.Atoi(fields[2])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", lineNo, err)
+			}
+			numClauses, err = strconv.Atoi(fields[3])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", lineNo, err)
+			}
+			continue
+		}
+		if numClauses < 0 {
+			return nil, fmt.Errorf("line %d: clause before problem line", lineNo)
+		}
+		for _, token := range strings.Fields(line) {
+			l, err := strconv.Atoi(token)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", lineNo, err)
+			}
+			if l == 0 {
+				f.AddClause(clause...)
+				clause = clause[:0]
+				continue
+			}
+			if Lit(l).Var() > f.NumVars {
+				return nil, fmt.Errorf("line %d: literal %d exceeds %d variables", lineNo, l, f.NumVars)
+			}
+			clause = append(clause, Lit(l))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if numClauses < 0 {
+		return nil, fmt.Errorf("missing problem line")
+	}
+	if len(clause) > 0 {
+		f.AddClause(clause...)
+	}
+	if len(f.Clauses) != numClauses {
+		return nil, fmt.Errorf("expected %d clauses, got %d", numClauses, len(f.Clauses))
+	}
+	return &f, nil
+}
+
+// WriteDIMACS writes the formula in DIMACS CNF format.
+func (f *Formula) WriteDIMACS(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "p cnf %d %d\n", f.NumVars, len(f.Clauses))
+	for _, c := range f.Clauses {
+		for _, l := range c {
+			bw.WriteString(strconv.Itoa(int(l)))
+			bw.WriteByte(' ')
+		}
+		bw.WriteString("0\n")
+	}
+	return bw.Flush()
+}
+
+// WriteModel writes the result in the output format of the SAT competitions.
+func WriteModel(w io.Writer, status Status, model []bool) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "s %s\n", status)
+	if status == Satisfiable {
+		bw.WriteString("v")
+		for v := 1; v < len(model); v++ {
+			l := v
+			if !model[v] {
+				l = -v
+			}
+			fmt.Fprintf(bw, " %d", l)
+		}
+		bw.WriteString(" 0\n")
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,169 @@
+// Package sat provides CNF formulas, cardinality encodings, DIMACS import/export and a small CDCL solver.
+package sat
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Lit is a literal in DIMACS notation: variable v (counted from 1) as v and its negation as -v.
+type Lit int
+
+// Var returns the variable of the literal.
+func (l Lit) Var() int {
+	if l < 0 {
+		return int(-l)
+	}
+	return int(l)
+}
+
+// Not returns the negation of the literal.
+func (l Lit) Not() Lit {
+	return -l
+}
+
+// Formula is a propositional formula in conjunctive normal form.
+type Formula struct {
+	NumVars int
+	Clauses [][]Lit
+}
+
+// NewVar adds a fresh variable to the formula and returns its positive literal.
+func (f *Formula) NewVar() Lit {
+	f.NumVars++
+	return Lit(f.NumVars)
+}
+
+// AddClause adds the disjunction of the given literals. An empty clause makes the formula unsatisfiable.
+func (f *Formula) AddClause(lits ...Lit) {
+	for _, l := range lits {
+		if l == 0 || l.Var() > f.NumVars {
+			panic(fmt.Sprintf("sat: literal %d out of range", l))
+		}
+	}
+	f.Clauses = append(f.Clauses, slices.Clone(lits))
+}
+
+// AtMostOne requires that at most one of the literals is true.
+func (f *Formula) AtMostOne(lits []Lit) {
+	// Pairwise exclusion is cheaper for a few literals, the ladder is linear for many.
+	if len(lits) <= 5 {
+		for i := 0; i < len(lits); i++ {
+			for j := i + 1; j < len(lits); j++ {
+				f.AddClause(lits[i].Not(), lits[j].Not())
+			}
+		}
+		return
+	}
+	// The ladder is the sequential counter with a single register per literal: prev stands for "one of the literals
+	// so far is true", and no later literal may join it.
+	prev := lits[0]
+	for i, x := range lits[1:] {
+		f.AddClause(prev.Not(), x.Not())
+		if i == len(lits)-2 {
+			break
+		}
+		s := f.NewVar()
+		f.AddClause(prev.Not(), s)
+		f.AddClause(x.Not(), s)
+		prev = s
+	}
+}
+
+// AtMostK requires that at most k of the literals are true.
+func (f *Formula) AtMostK(lits []Lit, k int) {
+	if k >= len(lits) {
+		return
+	}
+	if k <= 0 {
+		for _, l := range lits {
+			f.AddClause(l.Not())
+		}
+		return
+	}
+	r := f.counter(lits, k+1, true, false)
+	f.AddClause(r[len(lits)-1][k].Not())
+}
+
+// AtLeastK requires that at least k of the literals are true.
+func (f *Formula) AtLeastK(lits []Lit, k int) {
+	if k <= 0 {
+		return
+	}
+	if k > len(lits) {
+		f.AddClause()
+		return
+	}
+	r := f.counter(lits, k, false, true)
+	f.AddClause(r[len(lits)-1][k-1])
+}
+
+// Exactly requires that exactly k of the literals are true.
+func (f *Formula) Exactly(lits []Lit, k int) {
+	if k < 0 || k > len(lits) {
+		f.AddClause()
+		return
+	}
+	if k == 0 || k == len(lits) {
+		f.AtMostK(lits, k)
+		f.AtLeastK(lits, k)
+		return
+	}
+	r := f.counter(lits, k+1, true, true)
+	f.AddClause(r[len(lits)-1][k-1])
+	f.AddClause(r[len(lits)-1][k].Not())
+}
+
+// counter creates a sequential counter (Sinz 2005) over the literals: r[i][j] stands for "at least j+1 of the first
+// i+1 literals are true" for j < m. The upward clauses force the registers to true when the count is reached, the
+// downward clauses force them to false when it is not.
+func (f *Formula) counter(lits []Lit, m int, upward, downward bool) [][]Lit {
+	r := make([][]Lit, len(lits))
+	for i, x := range lits {
+		r[i] = make([]Lit, m)
+		for j := range r[i] {
+			r[i][j] = f.NewVar()
+		}
+		for j := 0; j < m; j++ {
+			if upward {
+				if i > 0 {
+					f.AddClause(r[i-1][j].Not(), r[i][j])
+				}
+				if j == 0 {
+					f.AddClause(x.Not(), r[i][j])
+				} else if i > 0 {
+					f.AddClause(x.Not(), r[i-1][j-1].Not(), r[i][j])
+				}
+			}
+			if downward {
+				if i > 0 {
+					f.AddClause(r[i][j].Not(), x, r[i-1][j])
+				} else {
+					f.AddClause(r[i][j].Not(), x)
+				}
+				if j > 0 {
+					if i > 0 {
+						f.AddClause(r[i][j].Not(), r[i-1][j-1])
+					} else {
+						f.AddClause(r[i][j].Not())
+					}
+				}
+			}
+		}
+	}
+	return r
+}
+
+// Satisfies checks if the assignment, indexed by variable, satisfies all clauses of the formula.
+func (f *Formula) Satisfies(model []bool) bool {
+Clauses:
+	for _, c := range f.Clauses {
+		for _, l := range c {
+			if model[l.Var()] == (l > 0) {
+				continue Clauses
+			}
+		}
+		return false
+	}
+	return true
+}
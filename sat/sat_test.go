@@ -0,0 +1,221 @@
+package sat
+
+import (
+	"bytes"
+	"math/bits"
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// pigeonhole encodes that n+1 pigeons sit in n holes, which is unsatisfiable.
+func pigeonhole(n int) *Formula {
+	var f Formula
+	p := make([][]Lit, n+1)
+	for i := range p {
+		for j := 0; j < n; j++ {
+			p[i] = append(p[i], f.NewVar())
+		}
+		f.AddClause(p[i]...)
+	}
+	for j := 0; j < n; j++ {
+		var hole []Lit
+		for i := range p {
+			hole = append(hole, p[i][j])
+		}
+		f.AtMostOne(hole)
+	}
+	return &f
+}
+
+func TestSolve(t *testing.T) {
+	t.Run("Pigeonhole", func(t *testing.T) {
+		for n := 1; n <= 6; n++ {
+			if got := NewSolver(pigeonhole(n)).Solve(nil); got != Unsatisfiable {
+				t.Errorf("n=%d: got %s, want %s", n, got, Unsatisfiable)
+			}
+		}
+	})
+	t.Run("Queens", func(t *testing.T) {
+		const n = 8
+		var f Formula
+		q := make([][]Lit, n)
+		for y := range q {
+			for x := 0; x < n; x++ {
+				q[y] = append(q[y], f.NewVar())
+			}
+		}
+		for i := 0; i < n; i++ {
+			var row, col []Lit
+			for j := 0; j < n; j++ {
+				row = append(row, q[i][j])
+				col = append(col, q[j][i])
+			}
+			f.Exactly(row, 1)
+			f.Exactly(col, 1)
+		}
+		for d := -n; d <= n; d++ {
+			var diag, anti []Lit
+			for y := 0; y < n; y++ {
+				if x := y + d; x >= 0 && x < n {
+					diag = append(diag, q[y][x])
+				}
+				if x := n - 1 - y + d; x >= 0 && x < n {
+					anti = append(anti, q[y][x])
+				}
+			}
+			f.AtMostOne(diag)
+			f.AtMostOne(anti)
+		}
+		s := NewSolver(&f)
+		if got := s.Solve(nil); got != Satisfiable {
+			t.Fatalf("got %s, want %s", got, Satisfiable)
+		}
+		if !f.Satisfies(s.Model()) {
+			t.Errorf("model does not satisfy the formula")
+		}
+	})
+}
+
+func TestRandom(t *testing.T) {
+	// Compare random 3-SAT instances around the phase transition against brute force.
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		var f Formula
+		f.NumVars = 3 + rng.Intn(10)
+		for j := 0; j < f.NumVars*43/10; j++ {
+			var c []Lit
+			for k := 0; k < 3; k++ {
+				l := Lit(1 + rng.Intn(f.NumVars))
+				if rng.Intn(2) == 0 {
+					l = l.Not()
+				}
+				c = append(c, l)
+			}
+			f.AddClause(c...)
+		}
+		want := Unsatisfiable
+		model := make([]bool, f.NumVars+1)
+		for a := 0; a < 1<<f.NumVars; a++ {
+			for v := 1; v <= f.NumVars; v++ {
+				model[v] = a&(1<<(v-1)) != 0
+			}
+			if f.Satisfies(model) {
+				want = Satisfiable
+				break
+			}
+		}
+		s := NewSolver(&f)
+		// The trace is a stack of assignments that ends on the model.
+		var trail []Lit
+		s.SetTrace(func(l Lit, assigned bool) {
+			if assigned {
+				trail = append(trail, l)
+			} else if n := len(trail) - 1; n >= 0 && trail[n] == l {
+				trail = trail[:n]
+			} else {
+				t.Fatalf("instance %d: got %d taken back, want the last of %v", i, l, trail)
+			}
+		})
+		got := s.Solve(nil)
+		if got != want {
+			t.Fatalf("instance %d: got %s, want %s", i, got, want)
+		}
+		if got != Satisfiable {
+			continue
+		}
+		if !f.Satisfies(s.Model()) {
+			t.Fatalf("instance %d: model does not satisfy the formula", i)
+		}
+		if len(trail) != f.NumVars {
+			t.Fatalf("instance %d: got %d traced assignments, want %d", i, len(trail), f.NumVars)
+		}
+		for _, l := range trail {
+			if s.Model()[l.Var()] != (l > 0) {
+				t.Fatalf("instance %d: got %d traced, which contradicts the model", i, l)
+			}
+		}
+	}
+}
+
+func TestCardinality(t *testing.T) {
+	// Count the models over the input literals by blocking each model found.
+	const n = 7
+	for k := 0; k <= n+1; k++ {
+		for _, tc := range []struct {
+			name   string
+			encode func(*Formula, []Lit, int)
+			accept func(int) bool
+		}{
+			{"AtMostK", (*Formula).AtMostK, func(c int) bool { return c <= k }},
+			{"AtLeastK", (*Formula).AtLeastK, func(c int) bool { return c >= k }},
+			{"Exactly", (*Formula).Exactly, func(c int) bool { return c == k }},
+			{"AtMostOne", func(f *Formula, lits []Lit, _ int) { f.AtMostOne(lits) }, func(c int) bool { return c <= 1 }},
+		} {
+			var f Formula
+			var lits []Lit
+			for i := 0; i < n; i++ {
+				lits = append(lits, f.NewVar())
+			}
+			tc.encode(&f, lits, k)
+			var want int
+			for m := 0; m < 1<<n; m++ {
+				if tc.accept(bits.OnesCount(uint(m))) {
+					want++
+				}
+			}
+			var got int
+			for {
+				s := NewSolver(&f)
+				if s.Solve(nil) != Satisfiable {
+					break
+				}
+				got++
+				var block []Lit
+				for _, l := range lits {
+					if s.Model()[l.Var()] {
+						block = append(block, l.Not())
+					} else {
+						block = append(block, l)
+					}
+				}
+				f.AddClause(block...)
+			}
+			if got != want {
+				t.Errorf("%s(%d of %d): got %d models, want %d", tc.name, k, n, got, want)
+			}
+		}
+	}
+}
+
+func TestDIMACS(t *testing.T) {
+	const input = `c A small example.
+p cnf 3 3
+1 -2 0
+2 3
+0 -1 0
+`
+	f, err := ReadDIMACS(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := [][]Lit{{1, -2}, {2, 3}, {-1}}
+	if f.NumVars != 3 || !slices.EqualFunc(f.Clauses, want, slices.Equal) {
+		t.Errorf("got %d vars and %v, want 3 vars and %v", f.NumVars, f.Clauses, want)
+	}
+	var buf bytes.Buffer
+	if err := f.WriteDIMACS(&buf); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	g, err := ReadDIMACS(&buf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if g.NumVars != f.NumVars || !slices.EqualFunc(g.Clauses, f.Clauses, slices.Equal) {
+		t.Errorf("round trip got %v, want %v", g.Clauses, f.Clauses)
+	}
+	if _, err := ReadDIMACS(strings.NewReader("p cnf 1 1\n2 0\n")); err == nil {
+		t.Errorf("expected error for out-of-range literal")
+	}
+}
@@ -0,0 +1,540 @@
+package sat
+
+import "slices"
+
+// Status is the outcome of a solver run.
+type Status int
+
+const (
+	Unknown Status = iota
+	Satisfiable
+	Unsatisfiable
+)
+
+func (s Status) String() string {
+	switch s {
+	case Satisfiable:
+		return "SATISFIABLE"
+	case Unsatisfiable:
+		return "UNSATISFIABLE"
+	}
+	return "UNKNOWN"
+}
+
+const (
+	restartBase    = 100
+	activityDecay  = 0.95
+	reduceInterval = 2000
+)
+
+// watcher refers to a clause from the watch list of one of its first two literals. The blocker is another literal of
+// the clause: if it is true, the clause is satisfied and need not be visited.
+type watcher struct {
+	clause  int
+	blocker Lit
+}
+
+// Solver is a conflict-driven clause learning (CDCL) solver with two watched literals, first-UIP clause learning with
+// minimization, VSIDS branching, phase saving and Luby restarts. Learned clauses are periodically reduced by their
+// literal block distance (LBD).
+type Solver struct {
+	numVars int
+	clauses [][]Lit
+	learnt  []bool
+	lbd     []int
+	watches [][]watcher // by watched literal, see index
+	unsat   bool
+
+	assign   []int8 // by variable: 1 true, -1 false, 0 unassigned
+	level    []int
+	reason   []int // implying clause, -1 for decisions and top-level facts
+	phase    []bool
+	trail    []Lit
+	trailLim []int
+	qhead    int
+
+	activity []float64
+	varInc   float64
+	order    varHeap
+	seen     []bool
+	levelSet map[int]struct{}
+
+	// Conflicts and Decisions count the search steps over all runs.
+	Conflicts, Decisions int
+	nextReduce           int
+	model                []bool
+	trace                func(l Lit, assigned bool)
+}
+
+// NewSolver creates a solver for the formula. The formula itself is not modified.
+func NewSolver(f *Formula) *Solver {
+	n := f.NumVars
+	s := &Solver{
+		numVars:    n,
+		watches:    make([][]watcher, 2*(n+1)),
+		assign:     make([]int8, n+1),
+		level:      make([]int, n+1),
+		reason:     make([]int, n+1),
+		phase:      make([]bool, n+1),
+		activity:   make([]float64, n+1),
+		seen:       make([]bool, n+1),
+		levelSet:   make(map[int]struct{}),
+		varInc:     1,
+		nextReduce: reduceInterval,
+	}
+	s.order.activity = s.activity
+	s.order.pos = make([]int, n+1)
+	for v := 1; v <= n; v++ {
+		s.order.pos[v] = -1
+		s.order.push(v)
+	}
+	for _, c := range f.Clauses {
+		s.addClause(c)
+	}
+	return s
+}
+
+// SetTrace sets a function that is called whenever the solver assigns a literal and when it takes the assignment
+// back, in reverse order of assignment. The literals that are assigned already are passed right away. It may be nil.
+func (s *Solver) SetTrace(trace func(l Lit, assigned bool)) {
+	s.trace = trace
+	if trace != nil {
+		for _, l := range s.trail {
+			trace(l, true)
+		}
+	}
+}
+
+func index(l Lit) int {
+	if l < 0 {
+		return 2*int(-l) + 1
+	}
+	return 2 * int(l)
+}
+
+func (s *Solver) value(l Lit) int8 {
+	if l < 0 {
+		return -s.assign[-l]
+	}
+	return s.assign[l]
+}
+
+// addClause adds an input clause at the top level.
+func (s *Solver) addClause(lits []Lit) {
+	if s.unsat {
+		return
+	}
+	// Normalize: drop duplicates and false literals, skip satisfied clauses and tautologies.
+	c := slices.Clone(lits)
+	slices.Sort(c)
+	c = slices.Compact(c)
+	n := 0
+	for i, l := range c {
+		if s.value(l) == 1 || (i+1 < len(c) && slices.Contains(c[i+1:], l.Not())) {
+			return
+		}
+		if s.value(l) == -1 {
+			continue
+		}
+		c[n] = l
+		n++
+	}
+	c = c[:n]
+	switch len(c) {
+	case 0:
+		s.unsat = true
+	case 1:
+		s.enqueue(c[0], -1)
+		if s.propagate() >= 0 {
+			s.unsat = true
+		}
+	default:
+		s.attach(c, false, 0)
+	}
+}
+
+func (s *Solver) attach(c []Lit, learnt bool, lbd int) int {
+	ci := len(s.clauses)
+	s.clauses = append(s.clauses, c)
+	s.learnt = append(s.learnt, learnt)
+	s.lbd = append(s.lbd, lbd)
+	s.watches[index(c[0])] = append(s.watches[index(c[0])], watcher{ci, c[1]})
+	s.watches[index(c[1])] = append(s.watches[index(c[1])], watcher{ci, c[0]})
+	return ci
+}
+
+func (s *Solver) decisionLevel() int {
+	return len(s.trailLim)
+}
+
+func (s *Solver) enqueue(l Lit, reason int) {
+	v := l.Var()
+	if l > 0 {
+		s.assign[v] = 1
+	} else {
+		s.assign[v] = -1
+	}
+	s.level[v] = s.decisionLevel()
+	s.reason[v] = reason
+	s.trail = append(s.trail, l)
+	if s.trace != nil {
+		s.trace(l, true)
+	}
+}
+
+// propagate performs unit propagation and returns the index of a conflicting clause, or -1.
+func (s *Solver) propagate() int {
+	for s.qhead < len(s.trail) {
+		falseLit := s.trail[s.qhead].Not()
+		s.qhead++
+		ws := s.watches[index(falseLit)]
+		j := 0
+		for i := 0; i < len(ws); i++ {
+			w := ws[i]
+			c := s.clauses[w.clause]
+			// Deleted clauses leave their watchers behind, which we drop here.
+			if c == nil {
+				continue
+			}
+			if s.value(w.blocker) == 1 {
+				ws[j] = w
+				j++
+				continue
+			}
+			// Make sure the false literal is the second watch.
+			if c[0] == falseLit {
+				c[0], c[1] = c[1], c[0]
+			}
+			w.blocker = c[0]
+			if s.value(c[0]) == 1 {
+				ws[j] = w
+				j++
+				continue
+			}
+			// Look for a new literal to watch.
+			moved := false
+			for k := 2; k < len(c); k++ {
+				if s.value(c[k]) != -1 {
+					c[1], c[k] = c[k], c[1]
+					s.watches[index(c[1])] = append(s.watches[index(c[1])], watcher{w.clause, c[0]})
+					moved = true
+					break
+				}
+			}
+			if moved {
+				continue
+			}
+			ws[j] = w
+			j++
+			if s.value(c[0]) == -1 {
+				j += copy(ws[j:], ws[i+1:])
+				s.watches[index(falseLit)] = ws[:j]
+				s.qhead = len(s.trail)
+				return w.clause
+			}
+			s.enqueue(c[0], w.clause)
+		}
+		s.watches[index(falseLit)] = ws[:j]
+	}
+	return -1
+}
+
+// analyze derives the first-UIP clause from the conflict and returns it along with the level to backjump to. The
+// asserting literal comes first, a literal of the backjump level second.
+func (s *Solver) analyze(confl int) ([]Lit, int) {
+	learnt := []Lit{0}
+	var p Lit
+	pending := 0
+	idx := len(s.trail) - 1
+	for {
+		c := s.clauses[confl]
+		start := 0
+		if p != 0 {
+			// The implied literal sits at the front of its reason.
+			start = 1
+		}
+		for _, q := range c[start:] {
+			v := q.Var()
+			if s.seen[v] || s.level[v] == 0 {
+				continue
+			}
+			s.seen[v] = true
+			s.bump(v)
+			if s.level[v] >= s.decisionLevel() {
+				pending++
+			} else {
+				learnt = append(learnt, q)
+			}
+		}
+		for !s.seen[s.trail[idx].Var()] {
+			idx--
+		}
+		p = s.trail[idx]
+		idx--
+		confl = s.reason[p.Var()]
+		s.seen[p.Var()] = false
+		pending--
+		if pending == 0 {
+			break
+		}
+	}
+	learnt[0] = p.Not()
+	// Drop literals that are implied by the other literals of the clause.
+	var kept []Lit
+	for _, l := range learnt[1:] {
+		if !s.redundant(l) {
+			kept = append(kept, l)
+		}
+	}
+	for _, l := range learnt[1:] {
+		s.seen[l.Var()] = false
+	}
+	learnt = append(learnt[:1], kept...)
+	btLevel := 0
+	for i := 1; i < len(learnt); i++ {
+		if lvl := s.level[learnt[i].Var()]; lvl > btLevel {
+			btLevel = lvl
+			learnt[1], learnt[i] = learnt[i], learnt[1]
+		}
+	}
+	return learnt, btLevel
+}
+
+// redundant checks if the literal of the learned clause is implied by the other ones, i.e., all literals of its reason
+// are in the clause as well or fixed at the top level.
+func (s *Solver) redundant(l Lit) bool {
+	r := s.reason[l.Var()]
+	if r < 0 {
+		return false
+	}
+	for _, q := range s.clauses[r][1:] {
+		if !s.seen[q.Var()] && s.level[q.Var()] > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// computeLBD counts the distinct decision levels in the clause.
+func (s *Solver) computeLBD(c []Lit) int {
+	clear(s.levelSet)
+	for _, l := range c {
+		s.levelSet[s.level[l.Var()]] = struct{}{}
+	}
+	return len(s.levelSet)
+}
+
+// reduce deletes half of the learned clauses, preferring those with a high LBD. Clauses that are the reason for a
+// current assignment and "glue" clauses with an LBD of 2 are kept.
+func (s *Solver) reduce() {
+	var candidates []int
+	for ci, c := range s.clauses {
+		if c == nil || !s.learnt[ci] || s.lbd[ci] <= 2 {
+			continue
+		}
+		if v := c[0].Var(); s.reason[v] == ci && s.value(c[0]) == 1 {
+			continue
+		}
+		candidates = append(candidates, ci)
+	}
+	slices.SortFunc(candidates, func(a, b int) int {
+		if s.lbd[a] != s.lbd[b] {
+			return s.lbd[b] - s.lbd[a]
+		}
+		return len(s.clauses[b]) - len(s.clauses[a])
+	})
+	for _, ci := range candidates[:len(candidates)/2] {
+		s.clauses[ci] = nil
+	}
+}
+
+func (s *Solver) cancelUntil(level int) {
+	if s.decisionLevel() <= level {
+		return
+	}
+	for i := len(s.trail) - 1; i >= s.trailLim[level]; i-- {
+		v := s.trail[i].Var()
+		if s.trace != nil {
+			s.trace(s.trail[i], false)
+		}
+		s.phase[v] = s.assign[v] == 1
+		s.assign[v] = 0
+		s.reason[v] = -1
+		if s.order.pos[v] < 0 {
+			s.order.push(v)
+		}
+	}
+	s.trail = s.trail[:s.trailLim[level]]
+	s.trailLim = s.trailLim[:level]
+	s.qhead = len(s.trail)
+}
+
+func (s *Solver) bump(v int) {
+	s.activity[v] += s.varInc
+	if s.activity[v] > 1e100 {
+		for i := range s.activity {
+			s.activity[i] *= 1e-100
+		}
+		s.varInc *= 1e-100
+	}
+	if s.order.pos[v] >= 0 {
+		s.order.up(s.order.pos[v])
+	}
+}
+
+func (s *Solver) pickBranch() Lit {
+	for s.order.len() > 0 {
+		v := s.order.pop()
+		if s.assign[v] != 0 {
+			continue
+		}
+		if s.phase[v] {
+			return Lit(v)
+		}
+		return Lit(-v)
+	}
+	return 0
+}
+
+// Solve searches for a satisfying assignment. It returns Unknown if stop is closed before the search ends; stop may
+// be nil. A satisfying assignment stays in place until the next run, so that a trace ends on the model.
+func (s *Solver) Solve(stop <-chan struct{}) Status {
+	if s.unsat {
+		return Unsatisfiable
+	}
+	s.cancelUntil(0)
+	restarts := 0
+	budget := restartBase * luby(restarts)
+	for {
+		if confl := s.propagate(); confl >= 0 {
+			s.Conflicts++
+			budget--
+			if s.decisionLevel() == 0 {
+				s.unsat = true
+				return Unsatisfiable
+			}
+			learnt, btLevel := s.analyze(confl)
+			s.cancelUntil(btLevel)
+			if len(learnt) == 1 {
+				s.enqueue(learnt[0], -1)
+			} else {
+				s.enqueue(learnt[0], s.attach(learnt, true, s.computeLBD(learnt)))
+			}
+			s.varInc /= activityDecay
+			continue
+		}
+		if budget <= 0 || s.Decisions%1024 == 1023 {
+			select {
+			case <-stop:
+				s.cancelUntil(0)
+				return Unknown
+			default:
+			}
+		}
+		if budget <= 0 {
+			restarts++
+			budget = restartBase * luby(restarts)
+			s.cancelUntil(0)
+			continue
+		}
+		if s.Conflicts >= s.nextReduce {
+			s.reduce()
+			s.nextReduce = s.Conflicts + reduceInterval + 300*restarts
+		}
+		l := s.pickBranch()
+		if l == 0 {
+			s.model = make([]bool, s.numVars+1)
+			for v := 1; v <= s.numVars; v++ {
+				s.model[v] = s.assign[v] == 1
+			}
+			return Satisfiable
+		}
+		s.Decisions++
+		s.trailLim = append(s.trailLim, len(s.trail))
+		s.enqueue(l, -1)
+	}
+}
+
+// Model returns the satisfying assignment of the last successful Solve, indexed by variable.
+func (s *Solver) Model() []bool {
+	return s.model
+}
+
+// luby returns the i-th element (counted from 0) of the Luby sequence 1, 1, 2, 1, 1, 2, 4, ...
+func luby(i int) int {
+	size, seq := 1, 0
+	for size < i+1 {
+		seq++
+		size = 2*size + 1
+	}
+	for size-1 != i {
+		size = (size - 1) / 2
+		seq--
+		i %= size
+	}
+	return 1 << seq
+}
+
+// varHeap is a max-heap of variables by activity.
+type varHeap struct {
+	heap     []int
+	pos      []int // position of each variable in heap, -1 if absent
+	activity []float64
+}
+
+func (h *varHeap) len() int {
+	return len(h.heap)
+}
+
+func (h *varHeap) less(i, j int) bool {
+	return h.activity[h.heap[i]] > h.activity[h.heap[j]]
+}
+
+func (h *varHeap) swap(i, j int) {
+	h.heap[i], h.heap[j] = h.heap[j], h.heap[i]
+	h.pos[h.heap[i]] = i
+	h.pos[h.heap[j]] = j
+}
+
+func (h *varHeap) push(v int) {
+	h.heap = append(h.heap, v)
+	h.pos[v] = len(h.heap) - 1
+	h.up(len(h.heap) - 1)
+}
+
+func (h *varHeap) pop() int {
+	v := h.heap[0]
+	last := len(h.heap) - 1
+	h.swap(0, last)
+	h.heap = h.heap[:last]
+	h.pos[v] = -1
+	h.down(0)
+	return v
+}
+
+func (h *varHeap) up(i int) {
+	for i > 0 {
+		parent := (i - 1) / 2
+		if !h.less(i, parent) {
+			return
+		}
+		h.swap(i, parent)
+		i = parent
+	}
+}
+
+func (h *varHeap) down(i int) {
+	for {
+		best := i
+		for _, c := range []int{2*i + 1, 2*i + 2} {
+			if c < len(h.heap) && h.less(c, best) {
+				best = c
+			}
+		}
+		if best == i {
+			return
+		}
+		h.swap(i, best)
+		i = best
+	}
+}